@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gorkolas/cybertantra/internal/altar"
+)
+
+// runAltar builds or previews a static personal site (ritual 13).
+func runAltar(args []string) error {
+	usage := func() {
+		fmt.Println("Usage: cybertantra altar build [-o DIR] [SRC]")
+		fmt.Println("       cybertantra altar serve [-o DIR] [-addr ADDR] [SRC]")
+		fmt.Println("\nSRC holds altar.json, posts/*.md, photos/, images/ and playlist.txt (default \".\").")
+	}
+	if len(args) == 0 {
+		usage()
+		return usagef("missing altar subcommand")
+	}
+	if isHelp(args[0]) {
+		usage()
+		return flag.ErrHelp
+	}
+
+	fs := flag.NewFlagSet("altar "+args[0], flag.ContinueOnError)
+	out := fs.String("o", "", "output `dir` (default SRC/public)")
+	fs.StringVar(out, "out", "", "output `dir`, as -o")
+	addr := fs.String("addr", "localhost:8080", "preview listen `address`")
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+	src := "."
+	if fs.NArg() > 0 {
+		src = fs.Arg(0)
+	}
+	if *out == "" {
+		*out = filepath.Join(src, "public")
+	}
+
+	switch args[0] {
+	case "build":
+		site, err := altar.Load(src)
+		if err != nil {
+			return err
+		}
+		if err := altar.Build(site, *out); err != nil {
+			return err
+		}
+		fmt.Printf("Altar built: %d posts, %d photos → %s\n", len(site.Posts), len(site.Photos), *out)
+		return nil
+
+	case "serve":
+		srv, err := altar.NewServer(src, *out)
+		if err != nil {
+			return err
+		}
+		log.Printf("Previewing altar on http://%s", *addr)
+		return http.ListenAndServe(*addr, srv)
+
+	default:
+		usage()
+		return usagef("unknown altar subcommand %q", args[0])
+	}
+}
@@ -0,0 +1,16 @@
+// Package assets holds the source text the reader is built from.
+package assets
+
+import _ "embed"
+
+// Manifesto is the whole document in markdown: the invocation, the
+// recommended reading and the footnotes it cites.
+//
+//go:embed manifesto.md
+var Manifesto string
+
+// Reflections is the question asked after each section of the
+// invocation, as markdown under the section's title.
+//
+//go:embed reflections.md
+var Reflections string
@@ -1,22 +1,12 @@
 package main
 
 import (
-	"context"
-	"errors"
 	"net"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
-	"github.com/charmbracelet/ssh"
-	"github.com/charmbracelet/wish"
-	"github.com/charmbracelet/wish/bubbletea"
-	"github.com/charmbracelet/wish/logging"
 
-	"github.com/gorkolas/cybertantra/internal/app"
+	"github.com/gorkolas/cybertantra/internal/server"
 )
 
 const (
@@ -24,44 +14,14 @@ const (
 	port = "2222"
 )
 
+// The standalone SSH server; "cybertantra serve ssh" is the same with flags.
 func main() {
-	s, err := wish.NewServer(
-		wish.WithAddress(net.JoinHostPort(host, port)),
-		wish.WithHostKeyPath(".ssh/id_ed25519"),
-		wish.WithMiddleware(
-			bubbletea.Middleware(teaHandler),
-			logging.Middleware(),
-		),
-	)
+	err := server.ServeSSH(server.SSHConfig{
+		Addr:    net.JoinHostPort(host, port),
+		HostKey: ".ssh/id_ed25519",
+	})
 	if err != nil {
 		log.Error("Could not start server", "error", err)
 		os.Exit(1)
 	}
-
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-
-	log.Info("Starting SSH server", "host", host, "port", port)
-	log.Info("Connect with: ssh -p 2222 localhost")
-
-	go func() {
-		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
-			log.Error("Could not start server", "error", err)
-			done <- nil
-		}
-	}()
-
-	<-done
-	log.Info("Stopping SSH server")
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
-		log.Error("Could not stop server", "error", err)
-	}
-}
-
-func teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
-	renderer := bubbletea.MakeRenderer(s)
-	m := app.New(renderer)
-	return m, []tea.ProgramOption{tea.WithAltScreen()}
 }
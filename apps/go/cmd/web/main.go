@@ -1,78 +1,20 @@
 package main
 
 import (
-	"embed"
-	"encoding/json"
-	"io"
 	"log"
-	"net/http"
 	"os"
-	"os/exec"
-	"os/signal"
-	"syscall"
 
-	"github.com/creack/pty"
-	"github.com/gorilla/websocket"
+	"github.com/gorkolas/cybertantra/internal/server"
 )
 
-type resizeMsg struct {
-	Cols uint16 `json:"cols"`
-	Rows uint16 `json:"rows"`
-}
-
-//go:embed static
-var staticFiles embed.FS
-
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins for dev
-	},
-}
-
+// The standalone web server runs a separately built cybertantra binary;
+// "cybertantra serve web" runs itself instead.
 func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	http.HandleFunc("/", serveIndex)
-	http.HandleFunc("/ws", handleWebSocket)
-
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-
-	log.Printf("Starting web server on http://localhost:%s", port)
-
-	go func() {
-		if err := http.ListenAndServe(":"+port, nil); err != nil {
-			log.Fatal(err)
-		}
-	}()
-
-	<-done
-	log.Println("Shutting down")
-}
-
-func serveIndex(w http.ResponseWriter, r *http.Request) {
-	data, err := staticFiles.ReadFile("static/index.html")
-	if err != nil {
-		http.Error(w, "Not found", http.StatusNotFound)
-		return
-	}
-	w.Header().Set("Content-Type", "text/html")
-	w.Write(data)
-}
-
-func handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
-	if err != nil {
-		log.Printf("Websocket upgrade error: %v", err)
-		return
-	}
-	defer conn.Close()
-
 	// Get the path to the cybertantra binary
 	binPath := "./cybertantra"
 	if _, err := os.Stat(binPath); os.IsNotExist(err) {
@@ -82,72 +24,7 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Start the TUI in a PTY
-	cmd := exec.Command(binPath)
-	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
-
-	ptmx, err := pty.Start(cmd)
-	if err != nil {
-		log.Printf("PTY start error: %v", err)
-		conn.WriteMessage(websocket.TextMessage, []byte("Error starting terminal: "+err.Error()))
-		return
-	}
-	defer func() {
-		ptmx.Close()
-		cmd.Process.Kill()
-	}()
-
-	// Set initial size
-	pty.Setsize(ptmx, &pty.Winsize{Rows: 24, Cols: 80})
-
-	// PTY -> WebSocket
-	go func() {
-		buf := make([]byte, 1024)
-		for {
-			n, err := ptmx.Read(buf)
-			if err != nil {
-				if err != io.EOF {
-					log.Printf("PTY read error: %v", err)
-				}
-				conn.Close()
-				return
-			}
-			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
-				log.Printf("WebSocket write error: %v", err)
-				return
-			}
-		}
-	}()
-
-	// WebSocket -> PTY
-	for {
-		msgType, msg, err := conn.ReadMessage()
-		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket read error: %v", err)
-			}
-			return
-		}
-
-		switch msgType {
-		case websocket.TextMessage:
-			// Check for resize message
-			if len(msg) > 0 && msg[0] == '{' {
-				var resize resizeMsg
-				if err := json.Unmarshal(msg, &resize); err == nil && resize.Cols > 0 && resize.Rows > 0 {
-					pty.Setsize(ptmx, &pty.Winsize{
-						Rows: resize.Rows,
-						Cols: resize.Cols,
-					})
-					continue // Don't write resize message to PTY
-				}
-			}
-			fallthrough
-		case websocket.BinaryMessage:
-			if _, err := ptmx.Write(msg); err != nil {
-				log.Printf("PTY write error: %v", err)
-				return
-			}
-		}
+	if err := server.ServeWeb(server.WebConfig{Addr: ":" + port, Command: []string{binPath}}); err != nil {
+		log.Fatal(err)
 	}
 }
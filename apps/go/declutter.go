@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gorkolas/cybertantra/internal/declutter"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// runDeclutter scans local mailboxes and opens the sender triage list.
+// Selected senders are written out as an unsubscribe plan; nothing is
+// fetched or sent.
+func runDeclutter(args []string) error {
+	fs := flag.NewFlagSet("declutter", flag.ContinueOnError)
+	out := fs.String("o", "", "write the plan to `file` instead of stdout")
+	format := fs.String("format", "text", "plan format: text or json")
+	all := fs.Bool("all", false, "skip the triage list and plan every sender with an exit")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cybertantra declutter [flags] MAILBOX...")
+		fmt.Fprintln(fs.Output(), "\nMAILBOX is an mbox file, a Maildir, or a directory holding either.")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return usagef("no mailbox given")
+	}
+	if *format != "text" && *format != "json" {
+		return usagef("unknown format %q", *format)
+	}
+
+	msgs, err := declutter.Scan(fs.Args())
+	if err != nil {
+		return err
+	}
+	senders := declutter.Group(msgs, declutter.SortVolume)
+	if len(senders) == 0 {
+		return errors.New("no messages found")
+	}
+
+	var chosen []declutter.Sender
+	if *all {
+		for _, s := range senders {
+			if s.CanUnsubscribe() {
+				chosen = append(chosen, s)
+			}
+		}
+	} else {
+		th, _ := sessionTheme(os.Getenv(theme.EnvVar))
+		p := tea.NewProgram(declutter.New(nil, senders, th), tea.WithAltScreen())
+		final, err := p.Run()
+		if err != nil {
+			return err
+		}
+		m := final.(declutter.Model)
+		if !m.Confirmed() {
+			return nil
+		}
+		chosen = m.Selected()
+	}
+	if len(chosen) == 0 {
+		return nil
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	plan := declutter.Plan(chosen)
+	if *format == "json" {
+		return declutter.WriteJSON(w, plan)
+	}
+	return declutter.WriteText(w, plan)
+}
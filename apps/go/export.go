@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+
+	"github.com/gorkolas/cybertantra/internal/book"
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// exportFormats maps file extensions to export formats.
+var exportFormats = map[string]string{
+	".md":       "md",
+	".markdown": "md",
+	".txt":      "txt",
+	".html":     "html",
+	".htm":      "html",
+	".epub":     "epub",
+}
+
+// runExport writes the invocation out for reading elsewhere.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	out := fs.String("o", "", "write to `FILE` instead of stdout")
+	format := fs.String("format", "", "output `FORMAT`: md, txt, html or epub (default from -o's extension, else md)")
+	width := fs.Int("width", 72, "wrap txt to `N` columns")
+	themeName := fs.String("theme", "", "colour html and epub with the theme called `NAME` (default Neon CRT)")
+	author := fs.String("author", "", "credit `NAME` as the epub's creator")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cybertantra export [flags]")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected argument %q", fs.Arg(0))
+	}
+	if *format == "" {
+		*format = "md"
+		if f, ok := exportFormats[strings.ToLower(filepath.Ext(*out))]; ok {
+			*format = f
+		}
+	}
+	switch *format {
+	case "md", "txt", "html":
+	case "epub":
+		if *out == "" && term.IsTerminal(int(os.Stdout.Fd())) {
+			return usagef("an epub is binary; write it with -o FILE or redirect stdout")
+		}
+	default:
+		return usagef("unknown format %q (want md, txt, html or epub)", *format)
+	}
+
+	themes, err := theme.All(theme.Dir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Themes: %v\n", err)
+	}
+	th, err := theme.Resolve(themes, *themeName)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	f := os.Stdout
+	if *out != "" {
+		if f, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+	w := bufio.NewWriter(f)
+	sections := invocation.Sections()
+	switch *format {
+	case "md":
+		err = invocation.WriteMarkdown(w, sections)
+	case "txt":
+		r := lipgloss.NewRenderer(w)
+		r.SetColorProfile(termenv.Ascii)
+		err = invocation.WriteText(w, sections, invocation.TextOptions{Width: *width, Renderer: r, Theme: th})
+	case "html":
+		err = book.WriteHTML(w, sections, th)
+	case "epub":
+		err = book.WriteEPUB(w, sections, book.EPUBOptions{Author: *author, Theme: th})
+	}
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if *out != "" {
+		return f.Close()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+	"github.com/gorkolas/cybertantra/internal/highlight"
+)
+
+// runHighlights writes out the lines highlighted while reading,
+// grouped by section, as markdown or JSON.
+func runHighlights(args []string) error {
+	fs := flag.NewFlagSet("highlights", flag.ContinueOnError)
+	out := fs.String("o", "", "write to `FILE` instead of stdout")
+	format := fs.String("format", "", "output `FORMAT`: md or json (default from -o's extension, else md)")
+	path := fs.String("file", datadir.Path("highlights.json"), "highlights `file` to read")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cybertantra highlights [flags]")
+		fmt.Fprintln(fs.Output(), "\nHighlight a line with m while reading, or a whole section with M.")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected argument %q", fs.Arg(0))
+	}
+	if *format == "" {
+		*format = "md"
+		if strings.EqualFold(filepath.Ext(*out), ".json") {
+			*format = "json"
+		}
+	}
+	if *format != "md" && *format != "json" {
+		return usagef("unknown format %q (want md or json)", *format)
+	}
+
+	s, err := highlight.Open(*path)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if *format == "json" {
+		return highlight.ExportJSON(w, s.All())
+	}
+	return highlight.ExportMarkdown(w, s.All())
+}
@@ -0,0 +1,153 @@
+package altar
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+var (
+	pages = template.Must(template.ParseFS(staticFiles, "static/layout.html"))
+	md    = goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		// It's the practitioner's own altar; raw HTML is part of the craft.
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+)
+
+// ring is the webring block state handed to templates.
+type ring struct {
+	Show       bool
+	Prev, Next Link
+}
+
+// page is the data every template receives.
+type page struct {
+	Root    string // Relative path back to the site root
+	Page    string // Page title, empty on the index
+	Config  Config
+	Site    *Site
+	Ring    ring
+	Post    Post
+	Content template.HTML
+}
+
+// Build renders the site into out: index, one page per post, the photo
+// log, the Atom feed, the stylesheet, and copies of photos/ and images/.
+func Build(s *Site, out string) error {
+	if err := os.MkdirAll(filepath.Join(out, postsDir), 0755); err != nil {
+		return err
+	}
+
+	base := page{Config: s.Config, Site: s}
+	base.Ring.Prev, base.Ring.Next, base.Ring.Show = s.Config.ringNeighbours()
+
+	if err := render(filepath.Join(out, "index.html"), "index", base); err != nil {
+		return err
+	}
+
+	photos := base
+	photos.Page = "photo log"
+	if err := render(filepath.Join(out, "photos.html"), "photos", photos); err != nil {
+		return err
+	}
+
+	for _, post := range s.Posts {
+		content, err := markdown(post.Body)
+		if err != nil {
+			return err
+		}
+		p := base
+		p.Root = "../"
+		p.Page = post.Title
+		p.Post = post
+		p.Content = content
+		if err := render(filepath.Join(out, postsDir, post.Slug+".html"), "post", p); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFeed(filepath.Join(out, "feed.xml"), s); err != nil {
+		return err
+	}
+
+	css, err := staticFiles.ReadFile("static/altar.css")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(out, "altar.css"), css, 0644); err != nil {
+		return err
+	}
+
+	for _, dir := range []string{photosDir, imagesDir} {
+		if err := copyDir(filepath.Join(s.Dir, dir), filepath.Join(out, dir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func render(path, name string, data page) error {
+	var buf bytes.Buffer
+	if err := pages.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func markdown(src string) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// copyDir mirrors src into dst. A missing src is not an error.
+func copyDir(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
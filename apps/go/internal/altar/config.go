@@ -0,0 +1,78 @@
+package altar
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ConfigFile is the per-altar settings file at the root of the source dir.
+const ConfigFile = "altar.json"
+
+// Link is one entry in the webring.
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Config describes the altar and the ring it belongs to.
+type Config struct {
+	Title       string `json:"title"`
+	Subtitle    string `json:"subtitle"`
+	Author      string `json:"author"`
+	URL         string `json:"url"` // Public base URL, used for the feed
+	Ring        string `json:"ring"`
+	Webring     []Link `json:"webring"`
+	FeedEntries int    `json:"feed_entries"`
+}
+
+// LoadConfig reads altar.json from dir. A missing file yields defaults
+// so a bare directory of posts still builds.
+func LoadConfig(dir string) (Config, error) {
+	cfg := Config{
+		Title:       filepath.Base(absDir(dir)),
+		Subtitle:    "every screen is an altar",
+		Ring:        "altar ring",
+		FeedEntries: 20,
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFile))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	if cfg.FeedEntries <= 0 {
+		cfg.FeedEntries = 20
+	}
+	return cfg, nil
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
+
+// ringNeighbours returns the previous and next altars around this one.
+// When the altar isn't listed itself it sits between the last and first.
+func (c Config) ringNeighbours() (prev, next Link, ok bool) {
+	n := len(c.Webring)
+	if n == 0 {
+		return Link{}, Link{}, false
+	}
+	for i, l := range c.Webring {
+		if c.URL != "" && l.URL == c.URL {
+			if n == 1 {
+				return Link{}, Link{}, false
+			}
+			return c.Webring[(i+n-1)%n], c.Webring[(i+1)%n], true
+		}
+	}
+	return c.Webring[n-1], c.Webring[0], true
+}
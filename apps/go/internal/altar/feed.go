@@ -0,0 +1,114 @@
+package altar
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Sub     string      `xml:"subtitle,omitempty"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Author  *atomPerson `xml:"author,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title   string    `xml:"title"`
+	ID      string    `xml:"id"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link"`
+	Content atomText  `xml:"content"`
+}
+
+// writeFeed writes an Atom 1.0 feed of the newest posts. With a URL in
+// the config, ids and links are absolute addresses under it. Without
+// one there is nowhere to link to, so the feed has no links and its ids
+// are name-based UUIDs, stable across builds.
+func writeFeed(path string, s *Site) error {
+	cfg := s.Config
+	baseURL := strings.TrimSuffix(cfg.URL, "/")
+	id := func(rel string) string {
+		if baseURL != "" {
+			return baseURL + "/" + rel
+		}
+		return nameUUID(cfg.Title + "\x00" + rel)
+	}
+
+	feed := atomFeed{
+		Title: cfg.Title,
+		Sub:   cfg.Subtitle,
+		ID:    id(""),
+	}
+	if baseURL != "" {
+		feed.Links = []atomLink{
+			{Href: baseURL + "/feed.xml", Rel: "self"},
+			{Href: baseURL + "/index.html"},
+		}
+	}
+	if cfg.Author != "" {
+		feed.Author = &atomPerson{Name: cfg.Author}
+	}
+
+	updated := time.Unix(0, 0).UTC()
+	for i, post := range s.Posts {
+		if i >= cfg.FeedEntries {
+			break
+		}
+		content, err := markdown(post.Body)
+		if err != nil {
+			return err
+		}
+		rel := postsDir + "/" + post.Slug + ".html"
+		entry := atomEntry{
+			Title:   post.Title,
+			ID:      id(rel),
+			Updated: post.Date.UTC().Format(time.RFC3339),
+			Content: atomText{Type: "html", Body: string(content)},
+		}
+		if baseURL != "" {
+			entry.Link = &atomLink{Href: baseURL + "/" + rel}
+		}
+		feed.Entries = append(feed.Entries, entry)
+		if post.Date.After(updated) {
+			updated = post.Date.UTC()
+		}
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), data...), 0644)
+}
+
+// nameUUID is a version 5 style UUID URN made from a hash of name, so
+// the same name always gives the same id.
+func nameUUID(name string) string {
+	h := sha1.Sum([]byte(name))
+	h[6] = h[6]&0x0f | 0x50
+	h[8] = h[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
@@ -0,0 +1,87 @@
+package altar
+
+import (
+	"io/fs"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server previews an altar locally, rebuilding when sources change.
+type Server struct {
+	src, out string
+
+	mu    sync.Mutex
+	built time.Time
+}
+
+// NewServer builds src into out once and returns a preview handler.
+func NewServer(src, out string) (*Server, error) {
+	s := &Server{src: src, out: out}
+	if err := s.rebuild(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Only page loads trigger the freshness check; assets ride along.
+	if r.URL.Path == "/" || strings.HasSuffix(r.URL.Path, ".html") || strings.HasSuffix(r.URL.Path, ".xml") {
+		if err := s.refresh(); err != nil {
+			log.Printf("Altar build error: %v", err)
+			http.Error(w, "Build failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	http.FileServer(http.Dir(s.out)).ServeHTTP(w, r)
+}
+
+func (s *Server) refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.changedSince(s.built) {
+		return nil
+	}
+	log.Printf("Sources changed, rebuilding %s", s.src)
+	return s.rebuildLocked()
+}
+
+func (s *Server) rebuild() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rebuildLocked()
+}
+
+func (s *Server) rebuildLocked() error {
+	site, err := Load(s.src)
+	if err != nil {
+		return err
+	}
+	s.built = time.Now()
+	return Build(site, s.out)
+}
+
+// changedSince walks the source tree, skipping the output directory.
+func (s *Server) changedSince(t time.Time) bool {
+	out, _ := filepath.Abs(s.out)
+	changed := false
+	filepath.WalkDir(s.src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || changed {
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			if abs, _ := filepath.Abs(path); abs == out {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(t) {
+			changed = true
+		}
+		return nil
+	})
+	return changed
+}
@@ -0,0 +1,271 @@
+package altar
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Source layout, relative to the altar directory.
+const (
+	postsDir  = "posts"
+	photosDir = "photos"
+	imagesDir = "images"
+)
+
+// playlistFiles are tried in order; the first one found is used.
+var playlistFiles = []string{"playlist.txt", "playlist.m3u", "playlist.m3u8"}
+
+var imageExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".avif": true,
+}
+
+var datePrefix = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-?`)
+
+// Post is one markdown file from posts/.
+type Post struct {
+	Title string
+	Slug  string
+	Date  time.Time
+	Body  string // Markdown without front matter
+}
+
+// Photo is one image from photos/, shown in the photo log.
+type Photo struct {
+	File    string // Name inside photos/
+	Caption string
+	Date    time.Time
+}
+
+// Track is one playlist entry.
+type Track struct {
+	Title string
+	URL   string
+}
+
+// Site is everything the generator reads from the source directory.
+type Site struct {
+	Dir      string
+	Config   Config
+	Posts    []Post  // Newest first
+	Photos   []Photo // Newest first
+	Playlist []Track
+}
+
+// Load reads config, posts, photos and playlist from dir.
+func Load(dir string) (*Site, error) {
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &Site{Dir: dir, Config: cfg}
+
+	if s.Posts, err = loadPosts(filepath.Join(dir, postsDir)); err != nil {
+		return nil, err
+	}
+	if s.Photos, err = loadPhotos(filepath.Join(dir, photosDir)); err != nil {
+		return nil, err
+	}
+	for _, name := range playlistFiles {
+		tracks, err := loadPlaylist(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.Playlist = tracks
+		break
+	}
+	return s, nil
+}
+
+func loadPosts(dir string) ([]Post, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []Post
+	var bases []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, parsePost(e.Name(), string(data), info.ModTime()))
+		bases = append(bases, strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+	}
+	// Posts that share a slug once their dates are stripped keep the
+	// date, so one page doesn't overwrite another
+	taken := map[string]int{}
+	for _, p := range posts {
+		taken[p.Slug]++
+	}
+	for i := range posts {
+		if taken[posts[i].Slug] > 1 {
+			posts[i].Slug = bases[i]
+		}
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Date.After(posts[j].Date)
+	})
+	return posts, nil
+}
+
+// parsePost reads optional "---" front matter (title:, date:), falling
+// back to the first "# " heading and a YYYY-MM-DD filename prefix.
+func parsePost(name, text string, modTime time.Time) Post {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	p := Post{Slug: datePrefix.ReplaceAllString(base, ""), Date: modTime}
+	if m := datePrefix.FindStringSubmatch(base); m != nil {
+		if d, err := time.Parse("2006-01-02", m[1]); err == nil {
+			p.Date = d
+		}
+	}
+	if p.Slug == "" {
+		p.Slug = base
+	}
+
+	if strings.HasPrefix(text, "---\n") {
+		if end := strings.Index(text[4:], "\n---"); end >= 0 {
+			front := text[4 : 4+end]
+			text = strings.TrimLeft(text[4+end+4:], "\n")
+			for _, line := range strings.Split(front, "\n") {
+				key, val, ok := strings.Cut(line, ":")
+				if !ok {
+					continue
+				}
+				val = strings.Trim(strings.TrimSpace(val), `"`)
+				switch strings.TrimSpace(key) {
+				case "title":
+					p.Title = val
+				case "date":
+					if d, err := time.Parse("2006-01-02", val); err == nil {
+						p.Date = d
+					}
+				}
+			}
+		}
+	}
+
+	if p.Title == "" {
+		for _, line := range strings.Split(text, "\n") {
+			if strings.HasPrefix(line, "# ") {
+				p.Title = strings.TrimSpace(line[2:])
+				text = strings.Replace(text, line+"\n", "", 1)
+				break
+			}
+		}
+	}
+	if p.Title == "" {
+		p.Title = strings.ReplaceAll(p.Slug, "-", " ")
+	}
+	p.Body = text
+	return p
+}
+
+// loadPhotos lists images in photos/. Captions come from captions.txt
+// ("file.jpg: caption" per line), otherwise from the filename.
+func loadPhotos(dir string) ([]Photo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	captions := make(map[string]string)
+	if f, err := os.Open(filepath.Join(dir, "captions.txt")); err == nil {
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			if file, caption, ok := strings.Cut(sc.Text(), ":"); ok {
+				captions[strings.TrimSpace(file)] = strings.TrimSpace(caption)
+			}
+		}
+		f.Close()
+	}
+
+	var photos []Photo
+	for _, e := range entries {
+		if e.IsDir() || !imageExts[strings.ToLower(filepath.Ext(e.Name()))] {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		base := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		ph := Photo{File: e.Name(), Caption: captions[e.Name()], Date: info.ModTime()}
+		if m := datePrefix.FindStringSubmatch(base); m != nil {
+			if d, err := time.Parse("2006-01-02", m[1]); err == nil {
+				ph.Date = d
+			}
+		}
+		if ph.Caption == "" {
+			ph.Caption = strings.ReplaceAll(datePrefix.ReplaceAllString(base, ""), "-", " ")
+		}
+		photos = append(photos, ph)
+	}
+	sort.SliceStable(photos, func(i, j int) bool {
+		return photos[i].Date.After(photos[j].Date)
+	})
+	return photos, nil
+}
+
+// loadPlaylist reads one track per line. Plain text lines may end in
+// " | URL"; M3U files use #EXTINF titles followed by the location.
+func loadPlaylist(path string) ([]Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tracks []Track
+	var pending string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "", line == "#EXTM3U":
+			continue
+		case strings.HasPrefix(line, "#EXTINF:"):
+			if _, title, ok := strings.Cut(line, ","); ok {
+				pending = strings.TrimSpace(title)
+			}
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		t := Track{Title: line}
+		if title, url, ok := strings.Cut(line, " | "); ok {
+			t = Track{Title: strings.TrimSpace(title), URL: strings.TrimSpace(url)}
+		} else if strings.HasPrefix(line, "http://") || strings.HasPrefix(line, "https://") {
+			t = Track{Title: line, URL: line}
+		}
+		if pending != "" {
+			t.Title = pending
+			pending = ""
+		}
+		tracks = append(tracks, t)
+	}
+	return tracks, sc.Err()
+}
@@ -2,63 +2,159 @@ package app
 
 import (
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/gorkolas/cybertantra/internal/highlight"
 	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/journal"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/search"
+	"github.com/gorkolas/cybertantra/internal/theme"
 )
 
-// View represents which content is being shown
-type View int
+// Options carries per-session dependencies: the local user's data, or
+// the SSH user's when served remotely.
+type Options struct {
+	Journal *journal.Journal // nil hides the journal and reflection prompts
+	Theme   theme.Theme      // Starting theme; the default when unset
+	Themes  []theme.Theme    // Cycled with t; the builtins when empty
+	Keys    keys.Map         // Key bindings; the defaults when unset
 
-const (
-	ViewMenu View = iota
-	ViewInvocation
-	ViewRituals
-)
+	Circadian  theme.Circadian // Shifts the theme with the time of day when enabled
+	Location   *time.Location  // The practitioner's time zone; local when nil
+	PaletteLog *theme.Log      // Records which palette was on screen; nil disables
 
-// Colors - neon CRT palette (brightened)
-var (
-	colorYellow  = lipgloss.Color("#ffef7c")
-	colorCyan    = lipgloss.Color("#5ad4ff")
-	colorMagenta = lipgloss.Color("#ff66cc")
-	colorGreen   = lipgloss.Color("#6dd835")
-	colorBright  = lipgloss.Color("#f0f0f0")
-	colorText    = lipgloss.Color("#d0d0d0")
-	colorFaded   = lipgloss.Color("#909090")
-	colorMuted   = lipgloss.Color("#707070")
-	colorDim     = lipgloss.Color("#505050")
-)
+	Section  int    // Open the invocation at this section (1-based) instead of the menu
+	Progress string // Where the invocation saves its place, to resume from; "" saves nothing
+	Pace     string // Where the practitioner's learned reading speed is kept; "" keeps it for the session
+
+	Highlights *highlight.Store // Lines marked while reading; nil hides highlighting
+	Links      bool             // The terminal opens OSC 8 hyperlinks, so notes' links are clickable
+}
 
 type Model struct {
-	view       View
-	selected   int
-	width      int
-	height     int
-	ready      bool
-	renderer   *lipgloss.Renderer
-	invocation tea.Model
+	selected  int
+	width     int
+	height    int
+	ready     bool
+	renderer  *lipgloss.Renderer
+	opts      Options
+	theme     theme.Theme
+	circadian bool // Following the schedule; off once t is pressed
+	keys      keys.Map
+	help      help.Model
+	showHelp  bool              // The ? overlay is open
+	stack     []page            // Screens open over the menu, the top one shown
+	kept      map[string]Screen // Screens popped off, resumed when opened again
 }
 
 type menuItem struct {
-	title string
-	desc  string
+	title  string
+	desc   string
+	screen *screen
+}
+
+func New(r *lipgloss.Renderer, opts Options) Model {
+	if len(opts.Themes) == 0 {
+		opts.Themes = theme.Builtin()
+	}
+	if opts.Theme.Name == "" {
+		opts.Theme = opts.Themes[0]
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+	m := Model{
+		renderer:  r,
+		opts:      opts,
+		theme:     opts.Theme,
+		circadian: opts.Circadian.Enabled,
+		keys:      opts.Keys.Or(),
+		kept:      make(map[string]Screen),
+	}
+	if m.circadian {
+		m.theme = m.scheduled(time.Now())
+	}
+	m.help = keys.NewHelp(r, m.theme)
+	if opts.Section > 0 {
+		m.stack = []page{{screenNamed("Invocation"), invocation.NewAtSection(r, opts.Section-1, m.invocationOptions())}}
+	} else if p, ok := invocation.LoadProgress(opts.Progress); ok {
+		// Last session's place, offered as a resume from the menu
+		m.kept["Invocation"] = invocation.NewFromProgress(r, p, m.invocationOptions())
+	}
+	return m
+}
+
+func (m Model) invocationOptions() invocation.Options {
+	return invocation.Options{Journal: m.opts.Journal, Theme: m.theme, Keys: m.keys, Progress: m.opts.Progress, Pace: m.opts.Pace, Highlights: m.opts.Highlights, Links: m.opts.Links}
+}
+
+type circadianMsg time.Time
+
+func circadianTick() tea.Cmd {
+	return tea.Tick(time.Minute, func(t time.Time) tea.Msg {
+		return circadianMsg(t)
+	})
+}
+
+// scheduled is the theme the circadian schedule wants at t, falling
+// back to the chosen theme for "" or a name that isn't installed.
+func (m Model) scheduled(t time.Time) theme.Theme {
+	if th, ok := theme.Find(m.opts.Themes, m.opts.Circadian.At(t.In(m.opts.Location))); ok {
+		return th
+	}
+	return m.opts.Theme
+}
+
+// nextTheme switches to the next theme by hand, which also stops the
+// circadian schedule for the rest of the session.
+func (m Model) nextTheme() (Model, tea.Cmd) {
+	m.circadian = false
+	return m.setTheme(theme.Next(m.opts.Themes, m.theme), theme.SourceManual)
 }
 
-var menuItems = []menuItem{
-	{"Enter", "Part I: The Invocation"},
+// setTheme records the change and tells every screen.
+func (m Model) setTheme(th theme.Theme, source string) (Model, tea.Cmd) {
+	m.theme = th
+	m.help = keys.NewHelp(m.renderer, th)
+	m.opts.PaletteLog.Record(th.Name, source)
+	return m.broadcast(theme.ChangedMsg{Theme: m.theme})
 }
 
-func New(r *lipgloss.Renderer) Model {
-	return Model{
-		view:     ViewMenu,
-		renderer: r,
+// menuItems are the registered screens on offer. One kept from last
+// time says it will resume.
+func (m Model) menuItems() []menuItem {
+	var items []menuItem
+	for i := range screens {
+		s := &screens[i]
+		if s.enabled != nil && !s.enabled(m) {
+			continue
+		}
+		desc := s.desc
+		if _, ok := m.kept[s.name]; ok {
+			desc = "resume · " + desc
+		}
+		items = append(items, menuItem{s.title, desc, s})
 	}
+	return items
 }
 
 func (m Model) Init() tea.Cmd {
-	return nil
+	m.opts.PaletteLog.Record(m.theme.Name, theme.SourceStart)
+	var cmds []tea.Cmd
+	if m.circadian {
+		cmds = append(cmds, circadianTick())
+	}
+	if p, ok := m.top(); ok {
+		cmds = append(cmds, p.Init())
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -69,132 +165,267 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ready = true
 	}
 
-	// If we're in a sub-view, delegate to it
-	if m.view == ViewInvocation {
+	if t, ok := msg.(circadianMsg); ok {
+		if !m.circadian {
+			return m, nil
+		}
 		var cmd tea.Cmd
-		m.invocation, cmd = m.invocation.Update(msg)
+		if th := m.scheduled(time.Time(t)); th.Name != m.theme.Name {
+			m, cmd = m.setTheme(th, theme.SourceCircadian)
+		}
+		return m, tea.Batch(cmd, circadianTick())
+	}
+
+	switch open := msg.(type) {
+	case search.OpenMsg:
+		return m.jump(open.Section, open.Line)
+	case search.PageMsg:
+		return m.jumpPage(open.Line)
+	case highlight.OpenMsg:
+		return m.jump(open.Section, open.Line)
+	}
 
-		// Check for quit or escape to return to menu
-		if keyMsg, ok := msg.(tea.KeyMsg); ok {
-			if keyMsg.String() == "esc" {
-				m.view = ViewMenu
-				return m, nil
+	// Any key or click closes the help overlay, and does nothing else
+	if m.showHelp {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			m.showHelp = false
+			return m, nil
+		case tea.MouseMsg:
+			if msg.Action == tea.MouseActionPress {
+				m.showHelp = false
 			}
+			return m, nil
+		}
+	}
+
+	// Sizes and themes reach every screen. Input, ticks and the rest go
+	// to the screen on top alone: one kept aside is paused, and picks its
+	// ticks up again when it resumes.
+	switch msg.(type) {
+	case tea.WindowSizeMsg, theme.ChangedMsg:
+		return m.broadcast(msg)
+	case tea.KeyMsg, tea.MouseMsg:
+	default:
+		if _, ok := m.top(); ok {
+			return m.updateTop(msg)
 		}
-		return m, cmd
+		return m, nil
+	}
+	if _, ok := m.top(); ok {
+		return m.updateScreen(msg)
+	}
+
+	if mouse, ok := msg.(tea.MouseMsg); ok && mouse.Action == tea.MouseActionPress {
+		return m.menuClick(mouse)
 	}
 
 	// Menu handling
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "ctrl+c", "q":
+		switch {
+		case key.Matches(keyMsg, m.keys.Quit):
 			return m, tea.Quit
-		case "up", "k":
+		case key.Matches(keyMsg, m.keys.Up):
 			if m.selected > 0 {
 				m.selected--
 			}
-		case "down", "j":
-			if m.selected < len(menuItems)-1 {
+		case key.Matches(keyMsg, m.keys.Down):
+			if m.selected < len(m.menuItems())-1 {
 				m.selected++
 			}
-		case "enter", " ":
+		case key.Matches(keyMsg, m.keys.Select):
 			return m.selectItem()
+		case key.Matches(keyMsg, m.keys.Search):
+			return m.push(screenNamed("Search"))
+		case key.Matches(keyMsg, m.keys.Theme):
+			return m.nextTheme()
+		case key.Matches(keyMsg, m.keys.Help):
+			m.showHelp = true
 		}
 	}
 
 	return m, nil
 }
 
-func (m Model) selectItem() (tea.Model, tea.Cmd) {
-	if m.selected == 0 {
-		m.view = ViewInvocation
-		m.invocation = invocation.New(m.renderer)
-		m.invocation, _ = m.invocation.Update(tea.WindowSizeMsg{
-			Width:  m.width,
-			Height: m.height,
-		})
-		return m, m.invocation.Init()
+// updateScreen hands input to the screen on top. Unless it is taking
+// text, the menu, theme and help keys are the app's: they work
+// everywhere, and the menu key pops the screen, keeping its place.
+// Over a screen that finds, the search key opens search.
+func (m Model) updateScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
+	p, _ := m.top()
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if isKey && !p.Capturing() {
+		switch {
+		case key.Matches(keyMsg, m.keys.Help):
+			m.showHelp = true
+			return m, nil
+		case key.Matches(keyMsg, m.keys.Theme):
+			return m.nextTheme()
+		case p.def.finds && key.Matches(keyMsg, m.keys.Search):
+			return m.push(screenNamed("Search"))
+		case key.Matches(keyMsg, m.keys.Menu):
+			// The screen sees it too: the journal lets its unlock prompt go
+			m, _ = m.updateTop(msg)
+			return m.pop(), nil
+		}
+	}
+	return m.updateTop(msg)
+}
+
+// menuClick opens the item under a click, and scrolls the selection
+// with the wheel.
+func (m Model) menuClick(mouse tea.MouseMsg) (tea.Model, tea.Cmd) {
+	switch mouse.Button {
+	case tea.MouseButtonWheelUp:
+		m.selected = max(0, m.selected-1)
+	case tea.MouseButtonWheelDown:
+		m.selected = min(len(m.menuItems())-1, m.selected+1)
+	case tea.MouseButtonLeft:
+		lines, rows := m.menuLayout()
+		y := mouse.Y - m.menuTop(len(lines))
+		for i, row := range rows {
+			// An item is its title and the description under it
+			if y == row || y == row+1 {
+				m.selected = i
+				return m.selectItem()
+			}
+		}
 	}
 	return m, nil
 }
 
+func (m Model) selectItem() (tea.Model, tea.Cmd) {
+	items := m.menuItems()
+	if m.selected >= len(items) {
+		return m, nil
+	}
+	return m.push(items[m.selected].screen)
+}
+
 func (m Model) View() string {
 	if !m.ready {
 		return ""
 	}
+	if layout.TooSmall(m.width, m.height) {
+		return layout.TooSmallView(m.width, m.height, m.tooSmallStyle())
+	}
+	if m.showHelp {
+		return m.viewHelp()
+	}
 
-	switch m.view {
-	case ViewInvocation:
-		return m.invocation.View()
-	case ViewRituals:
-		return m.viewRituals()
-	default:
-		return m.viewMenu()
+	if p, ok := m.top(); ok {
+		return p.View()
 	}
+	return m.viewMenu()
 }
 
-func (m Model) viewMenu() string {
+// tooSmallStyle draws the "terminal too small" notice.
+func (m Model) tooSmallStyle() lipgloss.Style {
 	r := m.renderer
 	if r == nil {
 		r = lipgloss.DefaultRenderer()
 	}
+	return r.NewStyle().Foreground(m.theme.Muted).Align(lipgloss.Center)
+}
 
-	w := m.width
-	if w < 40 {
-		w = 40
+// menuLayout builds the menu's lines, and the line each item's title
+// sits on, so clicks can be matched to what was drawn under them.
+func (m Model) menuLayout() (lines []string, rows []int) {
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
 	}
 
+	w := max(m.width, layout.MinWidth)
+	narrow, short := layout.Narrow(w), layout.Short(m.height)
+
+	quiet := theme.Degraded(r)
+
 	titleStyle := r.NewStyle().
-		Foreground(colorYellow).
+		Foreground(m.theme.Accent).
 		Bold(true).
 		Width(w).
 		Align(lipgloss.Center)
 
 	subtitleStyle := r.NewStyle().
-		Foreground(colorMuted).
+		Foreground(m.theme.Muted).
+		Faint(quiet).
 		Width(w).
 		Align(lipgloss.Center)
 
 	itemStyle := r.NewStyle().
-		Foreground(colorText).
+		Foreground(m.theme.Text).
 		Width(w).
 		Align(lipgloss.Center)
 
 	selectedStyle := r.NewStyle().
-		Foreground(colorCyan).
+		Foreground(m.theme.Title).
 		Bold(true).
 		Width(w).
 		Align(lipgloss.Center)
 
 	descStyle := r.NewStyle().
-		Foreground(colorFaded).
+		Foreground(m.theme.Faded).
+		Faint(quiet).
 		Width(w).
 		Align(lipgloss.Center)
 
 	blankLine := strings.Repeat(" ", w)
 
-	// Build content lines
-	var lines []string
-	lines = append(lines, titleStyle.Render("॥  C Y B E R T A N T R A  ॥"))
-	lines = append(lines, subtitleStyle.Render("the terminal is the temple"))
+	// Narrow terminals get the compact header; short ones lose the
+	// descriptions and the space between items.
+	if narrow {
+		lines = append(lines, titleStyle.Render("॥ CYBERTANTRA ॥"))
+	} else {
+		lines = append(lines, titleStyle.Render("॥  C Y B E R T A N T R A  ॥"))
+	}
+	if !short {
+		lines = append(lines, subtitleStyle.Render("the terminal is the temple"))
+	}
 	lines = append(lines, blankLine)
 
-	for i, item := range menuItems {
+	for i, item := range m.menuItems() {
+		rows = append(rows, len(lines))
 		if i == m.selected {
 			lines = append(lines, selectedStyle.Render("► "+item.title))
 		} else {
 			lines = append(lines, itemStyle.Render("  "+item.title))
 		}
-		lines = append(lines, descStyle.Render(item.desc))
+		if short {
+			continue
+		}
+		for _, desc := range layout.Wrap(item.desc, layout.TextWidth(w)) {
+			lines = append(lines, descStyle.Render(desc))
+		}
 		lines = append(lines, blankLine)
 	}
-
-	// Calculate padding
-	contentHeight := len(lines)
-	topPad := (m.height - contentHeight) / 2
-	if topPad < 0 {
-		topPad = 0
+	if short {
+		lines = append(lines, blankLine)
+	}
+	themeLine := m.theme.Name
+	if m.circadian {
+		themeLine += " (circadian)"
 	}
+	lines = append(lines, subtitleStyle.Render(themeLine))
+	if !short {
+		lines = append(lines, blankLine)
+	}
+	h := m.help
+	h.Width = w
+	lines = append(lines, lipgloss.PlaceHorizontal(w, lipgloss.Center, h.ShortHelpView(m.keys.MenuView().ShortHelp())))
+	return lines, rows
+}
+
+// menuTop is the blank lines above n lines of menu, centring it.
+func (m Model) menuTop(n int) int {
+	return max(0, (m.height-n)/2)
+}
+
+func (m Model) viewMenu() string {
+	w := max(m.width, layout.MinWidth)
+	blankLine := strings.Repeat(" ", w)
+	lines, _ := m.menuLayout()
+	topPad := m.menuTop(len(lines))
 
 	// Build full screen output
 	var b strings.Builder
@@ -227,39 +458,33 @@ func (m Model) viewMenu() string {
 	return b.String()
 }
 
-func (m Model) viewRituals() string {
-	var b strings.Builder
-
+// viewHelp is the ? overlay: every binding of the view underneath.
+func (m Model) viewHelp() string {
 	r := m.renderer
 	if r == nil {
 		r = lipgloss.DefaultRenderer()
 	}
+	quiet := theme.Degraded(r)
 
-	w := m.width
-	if w < 40 {
-		w = 40
+	bindings, name := m.keys.MenuView(), "Menu"
+	if p, ok := m.top(); ok {
+		bindings, name = p.def.keys(m.keys), p.def.name
 	}
 
-	titleStyle := r.NewStyle().
-		Foreground(colorCyan).
-		Bold(true).
-		Width(w).
-		Align(lipgloss.Center)
-
-	textStyle := r.NewStyle().
-		Foreground(colorText).
-		Width(w).
-		Align(lipgloss.Center)
-
-	topPad := (m.height - 5) / 2
-	if topPad < 0 {
-		topPad = 0
+	padY, padX := 1, 3
+	if layout.Narrow(m.width) || layout.Short(m.height) {
+		padY, padX = 0, 1
 	}
-	b.WriteString(strings.Repeat("\n", topPad))
-
-	b.WriteString(titleStyle.Render("Part IV: The Initiation Rituals"))
-	b.WriteString("\n\n\n")
-	b.WriteString(textStyle.Render("Coming soon..."))
-
-	return b.String()
+	h := m.help
+	h.Width = m.width - 2*padX - 2
+
+	title := r.NewStyle().Foreground(m.theme.Accent).Bold(true).Render("॥ KEYS · " + strings.ToUpper(name) + " ॥")
+	close := r.NewStyle().Foreground(m.theme.Muted).Faint(quiet).Render("any key to close")
+	body := lipgloss.JoinVertical(lipgloss.Center, title, "", h.FullHelpView(bindings.FullHelp()), "", close)
+	box := r.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Dim).
+		Padding(padY, padX).
+		Render(body)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
@@ -0,0 +1,229 @@
+package app
+
+import (
+	"maps"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gorkolas/cybertantra/internal/highlight"
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/journal"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/manifesto"
+	"github.com/gorkolas/cybertantra/internal/references"
+	"github.com/gorkolas/cybertantra/internal/search"
+)
+
+// Screen is a page opened over the menu, like the invocation or the
+// journal. Screens are pushed onto a stack; the menu key pops the top
+// one and keeps it, so opening it again picks up where it was left.
+type Screen interface {
+	tea.Model
+	// Capturing reports whether keystrokes are going into text entry,
+	// where the app's own keys must not be taken.
+	Capturing() bool
+}
+
+// resumer is a screen with something to refresh when it comes back
+// on top, such as journal entries written in the meantime, or ticks
+// that stopped reaching it while it was away.
+type resumer interface {
+	Resume() (tea.Model, tea.Cmd)
+}
+
+// screen registers a page the menu opens.
+type screen struct {
+	name    string // Names it in the help overlay, and keys what is kept
+	title   string // The menu item
+	desc    string
+	enabled func(Model) bool // Whether the menu offers it; nil is always
+	open    func(Model) Screen
+	keys    func(keys.Map) keys.View // Its bindings, for the help overlay
+	finds   bool                     // The search key opens search over it
+}
+
+// screens in menu order. A new page registers here.
+var screens = []screen{
+	{
+		name:  "Invocation",
+		title: "Enter",
+		desc:  invocation.Title,
+		open: func(m Model) Screen {
+			return invocation.New(m.renderer, m.invocationOptions())
+		},
+		keys:  keys.Map.ReaderView,
+		finds: true,
+	},
+	{
+		name:    "Journal",
+		title:   "Journal",
+		desc:    "Make the unconscious conscious",
+		enabled: func(m Model) bool { return m.opts.Journal != nil },
+		open: func(m Model) Screen {
+			return journal.New(m.renderer, m.opts.Journal, m.theme, m.keys)
+		},
+		keys: keys.Map.JournalView,
+	},
+	{
+		name:    "Highlights",
+		title:   "Highlights",
+		desc:    "The lines you kept",
+		enabled: func(m Model) bool { return m.opts.Highlights != nil },
+		open: func(m Model) Screen {
+			return highlight.New(m.renderer, m.opts.Highlights, m.theme, m.keys)
+		},
+		keys: keys.Map.HighlightsView,
+	},
+	{
+		name:  "Search",
+		title: "Search",
+		desc:  "Find where a word is spoken",
+		open: func(m Model) Screen {
+			return search.New(m.renderer, search.Default(), m.theme, m.keys)
+		},
+		keys: keys.Map.SearchView,
+	},
+	{
+		name:  "References",
+		title: "References",
+		desc:  "Notes and further reading",
+		open: func(m Model) Screen {
+			return references.New(m.renderer, m.theme, m.keys, m.opts.Links)
+		},
+		keys: keys.Map.ReferencesView,
+	},
+	{
+		name:  "Manifesto",
+		title: "Manifesto",
+		desc:  "The community, the devices and the rituals",
+		open: func(m Model) Screen {
+			return manifesto.New(m.renderer, m.theme, m.keys, m.opts.Links)
+		},
+		keys: keys.Map.ReferencesView,
+	},
+}
+
+// screenNamed finds a registered screen.
+func screenNamed(name string) *screen {
+	for i := range screens {
+		if screens[i].name == name {
+			return &screens[i]
+		}
+	}
+	return nil
+}
+
+// page is a screen open on the stack.
+type page struct {
+	def *screen
+	Screen
+}
+
+// top is the screen being shown, if any is open over the menu.
+func (m Model) top() (page, bool) {
+	if len(m.stack) == 0 {
+		return page{}, false
+	}
+	return m.stack[len(m.stack)-1], true
+}
+
+// push opens a screen over whatever is showing, resuming the one kept
+// from last time, or starting it sized to the terminal.
+func (m Model) push(def *screen) (Model, tea.Cmd) {
+	s, kept := m.kept[def.name]
+	if !kept {
+		return m.start(def, def.open(m))
+	}
+	var cmd tea.Cmd
+	m = m.forget(def.name)
+	if r, ok := s.(resumer); ok {
+		var next tea.Model
+		next, cmd = r.Resume()
+		s = next.(Screen)
+	}
+	m.stack = append(slices.Clip(m.stack), page{def, s})
+	return m, cmd
+}
+
+// start opens a new screen over whatever is showing, sized to the
+// terminal.
+func (m Model) start(def *screen, s Screen) (Model, tea.Cmd) {
+	next, _ := s.Update(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	s = next.(Screen)
+	m.stack = append(slices.Clip(m.stack), page{def, s})
+	return m, s.Init()
+}
+
+// jump opens the invocation at a section's body line, in place of
+// wherever it was left, from the search or highlights on top, which is
+// kept to come back to.
+func (m Model) jump(section, line int) (Model, tea.Cmd) {
+	return m.reopen(screenNamed("Invocation"), invocation.NewAtLine(m.renderer, section, line, m.invocationOptions()))
+}
+
+// jumpPage opens the manifesto at a line past the invocation, as jump.
+func (m Model) jumpPage(line int) (Model, tea.Cmd) {
+	return m.reopen(screenNamed("Manifesto"), manifesto.NewAt(m.renderer, m.theme, m.keys, m.opts.Links, line))
+}
+
+// reopen shows s as def's screen, dropping the one open or kept, and
+// keeping the screen on top that asked for it.
+func (m Model) reopen(def *screen, s Screen) (Model, tea.Cmd) {
+	if p, ok := m.top(); ok && p.def != def {
+		m = m.pop()
+	}
+	m = m.forget(def.name)
+	m.stack = slices.DeleteFunc(slices.Clone(m.stack), func(p page) bool { return p.def == def })
+	return m.start(def, s)
+}
+
+// pop closes the top screen, keeping it to resume.
+func (m Model) pop() Model {
+	p, ok := m.top()
+	if !ok {
+		return m
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+	m.kept = maps.Clone(m.kept)
+	m.kept[p.def.name] = p.Screen
+	return m
+}
+
+// forget drops the screen kept under name. The map is copied first,
+// never changed in place: earlier copies of the model share it.
+func (m Model) forget(name string) Model {
+	if _, ok := m.kept[name]; ok {
+		m.kept = maps.Clone(m.kept)
+		delete(m.kept, name)
+	}
+	return m
+}
+
+// updateTop hands msg to the screen being shown.
+func (m Model) updateTop(msg tea.Msg) (Model, tea.Cmd) {
+	i := len(m.stack) - 1
+	next, cmd := m.stack[i].Update(msg)
+	m.stack = slices.Clone(m.stack)
+	m.stack[i].Screen = next.(Screen)
+	return m, cmd
+}
+
+// broadcast hands msg to every screen, open or kept, so sizes and
+// themes reach them wherever they are.
+func (m Model) broadcast(msg tea.Msg) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	m.stack = slices.Clone(m.stack)
+	for i, p := range m.stack {
+		next, cmd := p.Update(msg)
+		m.stack[i].Screen = next.(Screen)
+		cmds = append(cmds, cmd)
+	}
+	m.kept = maps.Clone(m.kept)
+	for name, s := range m.kept {
+		next, cmd := s.Update(msg)
+		m.kept[name] = next.(Screen)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
@@ -0,0 +1,141 @@
+// Package book typesets the invocation for reading away from the
+// terminal: an EPUB 3 for e-readers and a single-file HTML page, both
+// built from the same sections the reader reveals.
+package book
+
+import (
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+var (
+	pages      = template.Must(template.ParseFS(staticFiles, "static/*.xhtml", "static/*.html"))
+	stylesheet = texttemplate.Must(texttemplate.ParseFS(staticFiles, "static/style.css"))
+)
+
+var boldPattern = regexp.MustCompile(`\*\*(.+?)\*\*`)
+
+// chapter is one section as markup.
+type chapter struct {
+	ID      string // Anchor in the HTML page, file name stem in the EPUB
+	Href    string // Where the chapter lives, for the table of contents
+	Title   string
+	KeyLine template.HTML
+	Stanzas [][]template.HTML // Verse lines, grouped between blank lines
+}
+
+// endnote is a note as markup, with the way back to its citation.
+type endnote struct {
+	N    int
+	Back string
+	Text template.HTML
+}
+
+// manuscript is the whole book as templates see it.
+type manuscript struct {
+	Title    string
+	Lang     string
+	Chapters []chapter
+	Notes    []endnote
+	NotesRef string       // Where endnotes live
+	Style    template.CSS // Stylesheet for the single-file page
+}
+
+// page is what a single EPUB document's template receives.
+type page struct {
+	Book    manuscript
+	Chapter chapter
+}
+
+// layout says where chapters and endnotes live, so the same manuscript
+// links across the EPUB's files or within the one HTML page.
+type layout struct {
+	chapterHref func(id string) string
+	notesHref   string
+	epub        bool // Mark note references for e-readers' popups
+}
+
+// typeset turns sections into a manuscript laid out by l.
+func typeset(sections []invocation.Section, l layout) manuscript {
+	notes := invocation.Endnotes(sections)
+	m := manuscript{Title: invocation.Title, Lang: "en", NotesRef: l.notesHref}
+	refAttr := `role="doc-noteref"`
+	if l.epub {
+		refAttr = `epub:type="noteref"`
+	}
+
+	for i, s := range sections {
+		c := chapter{ID: fmt.Sprintf("section-%d", i+1), Title: s.Title}
+		c.Href = l.chapterHref(c.ID)
+		c.KeyLine = inline(s.KeyLine)
+
+		var stanza []template.HTML
+		for _, line := range s.Lines {
+			if line == "" {
+				if len(stanza) > 0 {
+					c.Stanzas = append(c.Stanzas, stanza)
+				}
+				stanza = nil
+				continue
+			}
+			line := template.HTML(invocation.ReplaceNoteRefs(string(inline(line)), func(id string) string {
+				n := invocation.EndnoteNumber(notes, i, id)
+				if n == 0 {
+					return ""
+				}
+				return fmt.Sprintf(`<sup><a %s id="ref-%d" href="%s#note-%d">%d</a></sup>`, refAttr, n, l.notesHref, n, n)
+			}))
+			stanza = append(stanza, line)
+		}
+		if len(stanza) > 0 {
+			c.Stanzas = append(c.Stanzas, stanza)
+		}
+		m.Chapters = append(m.Chapters, c)
+	}
+
+	for _, n := range notes {
+		m.Notes = append(m.Notes, endnote{
+			N:    n.N,
+			Back: fmt.Sprintf("%s#ref-%d", doc(m.Chapters[n.Section].Href), n.N),
+			Text: inline(n.Text),
+		})
+	}
+	return m
+}
+
+// doc is the document part of href: "" for an anchor in the same page.
+func doc(href string) string {
+	d, _, _ := strings.Cut(href, "#")
+	return d
+}
+
+// inline renders a line's markdown: **bold** and [label](url) links.
+// Note markers pass through for the caller to resolve.
+func inline(s string) template.HTML {
+	s = html.EscapeString(s)
+	s = boldPattern.ReplaceAllString(s, "<strong>$1</strong>")
+	s = invocation.ReplaceLinks(s, func(label, url string) string {
+		return fmt.Sprintf(`<a href="%s">%s</a>`, url, label)
+	})
+	return template.HTML(s)
+}
+
+// style renders the stylesheet in th's colours.
+func style(th theme.Theme) (string, error) {
+	var b strings.Builder
+	if err := stylesheet.ExecuteTemplate(&b, "style.css", th); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
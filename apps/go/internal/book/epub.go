@@ -0,0 +1,212 @@
+package book
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// EPUBOptions carries what the content model doesn't know.
+type EPUBOptions struct {
+	Author   string
+	Modified time.Time // Stamped as dcterms:modified; zero means now
+	Theme    theme.Theme
+}
+
+// The OPF package document, as much of it as the book needs.
+type opfPackage struct {
+	XMLName  xml.Name     `xml:"http://www.idpf.org/2007/opf package"`
+	Version  string       `xml:"version,attr"`
+	UniqueID string       `xml:"unique-identifier,attr"`
+	Lang     string       `xml:"xml:lang,attr"`
+	Metadata opfMetadata  `xml:"metadata"`
+	Manifest []opfItem    `xml:"manifest>item"`
+	Spine    []opfItemRef `xml:"spine>itemref"`
+}
+
+type opfMetadata struct {
+	DC         string    `xml:"xmlns:dc,attr"`
+	Identifier opfID     `xml:"dc:identifier"`
+	Title      string    `xml:"dc:title"`
+	Language   string    `xml:"dc:language"`
+	Creator    string    `xml:"dc:creator,omitempty"`
+	Meta       []opfMeta `xml:"meta"`
+}
+
+type opfID struct {
+	ID    string `xml:"id,attr"`
+	Value string `xml:",chardata"`
+}
+
+type opfMeta struct {
+	Property string `xml:"property,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr,omitempty"`
+}
+
+type opfItemRef struct {
+	IDRef  string `xml:"idref,attr"`
+	Linear string `xml:"linear,attr,omitempty"`
+}
+
+const container = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+const xhtmlType = "application/xhtml+xml"
+
+// WriteEPUB writes sections as an EPUB 3: a title page, one chapter per
+// section, a navigation document, and the notes gathered as endnotes.
+func WriteEPUB(w io.Writer, sections []invocation.Section, opts EPUBOptions) error {
+	if opts.Modified.IsZero() {
+		opts.Modified = time.Now()
+	}
+	m := typeset(sections, layout{
+		chapterHref: func(id string) string { return id + ".xhtml" },
+		notesHref:   "notes.xhtml",
+		epub:        true,
+	})
+	css, err := style(opts.Theme)
+	if err != nil {
+		return err
+	}
+
+	// Every document, in reading order, rendered up front so a template
+	// error never leaves half a zip behind.
+	type doc struct {
+		id, href string
+		data     []byte
+		nav      bool
+	}
+	var docs []doc
+	render := func(id, href, tmpl string, c chapter) error {
+		// html/template would escape the declaration; it goes in first
+		b := bytes.NewBufferString(xml.Header)
+		if err := pages.ExecuteTemplate(b, tmpl, page{Book: m, Chapter: c}); err != nil {
+			return err
+		}
+		docs = append(docs, doc{id: id, href: href, data: b.Bytes(), nav: tmpl == "nav.xhtml"})
+		return nil
+	}
+	if err := render("title", "title.xhtml", "title.xhtml", chapter{}); err != nil {
+		return err
+	}
+	if err := render("nav", "nav.xhtml", "nav.xhtml", chapter{}); err != nil {
+		return err
+	}
+	for _, c := range m.Chapters {
+		if err := render(c.ID, c.Href, "chapter.xhtml", c); err != nil {
+			return err
+		}
+	}
+	if len(m.Notes) > 0 {
+		if err := render("notes", m.NotesRef, "notes.xhtml", chapter{}); err != nil {
+			return err
+		}
+	}
+
+	pkg := opfPackage{
+		Version:  "3.0",
+		UniqueID: "book-id",
+		Lang:     m.Lang,
+		Metadata: opfMetadata{
+			DC:         "http://purl.org/dc/elements/1.1/",
+			Identifier: opfID{ID: "book-id", Value: identifier(m)},
+			Title:      m.Title,
+			Language:   m.Lang,
+			Creator:    opts.Author,
+			Meta: []opfMeta{{
+				Property: "dcterms:modified",
+				Value:    opts.Modified.UTC().Format("2006-01-02T15:04:05Z"),
+			}},
+		},
+		Manifest: []opfItem{{ID: "style", Href: "style.css", MediaType: "text/css"}},
+	}
+	for _, d := range docs {
+		item := opfItem{ID: d.id, Href: d.href, MediaType: xhtmlType}
+		if d.nav {
+			item.Properties = "nav"
+		}
+		pkg.Manifest = append(pkg.Manifest, item)
+		pkg.Spine = append(pkg.Spine, opfItemRef{IDRef: d.id})
+	}
+	opf, err := xml.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	z := zip.NewWriter(w)
+	// The mimetype must come first, stored uncompressed with its sizes
+	// up front, so readers can sniff the format from a fixed offset.
+	mimetype := []byte("application/epub+zip")
+	mt, err := z.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		Modified:           opts.Modified,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := mt.Write(mimetype); err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"META-INF/container.xml", []byte(container)},
+		{"OEBPS/content.opf", append([]byte(xml.Header), opf...)},
+		{"OEBPS/style.css", []byte(css)},
+	}
+	for _, d := range docs {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{"OEBPS/" + d.href, d.data})
+	}
+	for _, f := range files {
+		fw, err := z.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: opts.Modified})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// identifier derives a stable urn:uuid from the book's content, so the
+// same text exports as the same book and e-readers don't duplicate it.
+func identifier(m manuscript) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", m.Title)
+	for _, c := range m.Chapters {
+		fmt.Fprintf(h, "%s\x00%s\x00%v\x00", c.Title, c.KeyLine, c.Stanzas)
+	}
+	sum := h.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50 // Name-based version
+	sum[8] = sum[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,23 @@
+package book
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// WriteHTML writes sections as one self-contained HTML page: the
+// stylesheet inline, a table of contents, and endnotes at the bottom.
+func WriteHTML(w io.Writer, sections []invocation.Section, th theme.Theme) error {
+	m := typeset(sections, layout{
+		chapterHref: func(id string) string { return "#" + id },
+	})
+	css, err := style(th)
+	if err != nil {
+		return err
+	}
+	m.Style = template.CSS(css)
+	return pages.ExecuteTemplate(w, "book.html", m)
+}
@@ -0,0 +1,36 @@
+// Package datadir resolves where cybertantra keeps per-user state.
+package datadir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns $CYBERTANTRA_HOME, or ~/.cybertantra when unset.
+func Dir() string {
+	if dir := os.Getenv("CYBERTANTRA_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".cybertantra"
+	}
+	return filepath.Join(home, ".cybertantra")
+}
+
+// Path joins elem onto Dir.
+func Path(elem ...string) string {
+	return filepath.Join(append([]string{Dir()}, elem...)...)
+}
+
+// Ensure creates the directory at Path(elem...) and returns it.
+func Ensure(elem ...string) (string, error) {
+	dir := Path(elem...)
+	return dir, os.MkdirAll(dir, 0700)
+}
+
+// UserDir is the per-user subtree used by the SSH server, keyed by an
+// identity such as a public key fingerprint.
+func UserDir(id string) string {
+	return Path("users", id)
+}
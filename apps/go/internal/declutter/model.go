@@ -0,0 +1,208 @@
+package declutter
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Model is the triage list: senders ranked by volume, toggled for the plan.
+type Model struct {
+	senders   []Sender
+	chosen    map[string]bool
+	order     SortOrder
+	cursor    int
+	offset    int
+	width     int
+	height    int
+	ready     bool
+	confirmed bool
+	theme     theme.Theme
+	renderer  *lipgloss.Renderer
+}
+
+func New(r *lipgloss.Renderer, senders []Sender, th theme.Theme) Model {
+	return Model{
+		senders:  senders,
+		chosen:   make(map[string]bool),
+		theme:    th,
+		renderer: r,
+	}
+}
+
+// Confirmed reports whether the list was closed with the export key.
+func (m Model) Confirmed() bool {
+	return m.confirmed
+}
+
+// Selected returns the chosen senders in ranked order.
+func (m Model) Selected() []Sender {
+	var out []Sender
+	for _, s := range m.senders {
+		if m.chosen[s.Key] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "enter", "x":
+			m.confirmed = true
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.senders)-1 {
+				m.cursor++
+			}
+		case "pgup":
+			m.cursor -= m.listHeight()
+			if m.cursor < 0 {
+				m.cursor = 0
+			}
+		case "pgdown":
+			m.cursor += m.listHeight()
+			if m.cursor > len(m.senders)-1 {
+				m.cursor = len(m.senders) - 1
+			}
+		case " ":
+			if m.cursor < len(m.senders) {
+				key := m.senders[m.cursor].Key
+				m.chosen[key] = !m.chosen[key]
+			}
+		case "a":
+			// Select every sender that offers a way out
+			for _, s := range m.senders {
+				if s.CanUnsubscribe() {
+					m.chosen[s.Key] = true
+				}
+			}
+		case "n":
+			m.chosen = make(map[string]bool)
+		case "s":
+			if m.order == SortVolume {
+				m.order = SortUnread
+			} else {
+				m.order = SortVolume
+			}
+			Sort(m.senders, m.order)
+			m.cursor = 0
+		}
+	}
+
+	// Keep the cursor inside the visible window
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+	return m, nil
+}
+
+// listHeight is the number of sender rows that fit between header and footer.
+func (m Model) listHeight() int {
+	h := m.height - 6
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+
+	th := m.theme
+	quiet := theme.Degraded(r)
+	titleStyle := r.NewStyle().Foreground(th.Accent).Bold(true)
+	headStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+	rowStyle := r.NewStyle().Foreground(th.Text)
+	cursorStyle := r.NewStyle().Foreground(th.Title).Bold(true)
+	hintStyle := r.NewStyle().Foreground(th.Faded).Faint(quiet)
+
+	var b strings.Builder
+	order := "volume"
+	if m.order == SortUnread {
+		order = "unread"
+	}
+	b.WriteString(titleStyle.Render("॥ INBOX DECLUTTER ॥"))
+	b.WriteString(headStyle.Render(fmt.Sprintf("  %d senders · %d chosen · by %s", len(m.senders), len(m.Selected()), order)))
+	b.WriteString("\n\n")
+	b.WriteString(headStyle.Render("      msgs  unread  exit      sender"))
+	b.WriteString("\n")
+
+	nameWidth := m.width - 34
+	if nameWidth < 10 {
+		nameWidth = 10
+	}
+
+	h := m.listHeight()
+	for i := m.offset; i < len(m.senders) && i < m.offset+h; i++ {
+		s := m.senders[i]
+		mark := "[ ]"
+		if m.chosen[s.Key] {
+			mark = "[x]"
+		}
+		exit := "—"
+		switch {
+		case s.OneClick:
+			exit = "1-click"
+		case len(s.URLs) > 0:
+			exit = "link"
+		case len(s.Mailto) > 0:
+			exit = "mailto"
+		}
+		name := s.Label()
+		if s.ListID != "" && s.Name != "" {
+			name += " (" + s.ListID + ")"
+		}
+		if len([]rune(name)) > nameWidth {
+			name = string([]rune(name)[:nameWidth-1]) + "…"
+		}
+		row := fmt.Sprintf("%s %6d  %5.0f%%  %-8s  %s", mark, s.Count, s.UnreadRatio()*100, exit, name)
+
+		if i == m.cursor {
+			b.WriteString(cursorStyle.Render("► " + row))
+		} else {
+			b.WriteString(rowStyle.Render("  " + row))
+		}
+		b.WriteString("\n")
+	}
+
+	for i := len(m.senders) - m.offset; i < h; i++ {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(hintStyle.Render("space toggle · a all with exits · n none · s sort · enter export plan · q quit"))
+
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package declutter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// PlanEntry is one sender's way out. Nothing here is ever fetched or
+// sent; the plan is for the practitioner to carry out.
+type PlanEntry struct {
+	Sender   string   `json:"sender"`
+	Address  string   `json:"address"`
+	ListID   string   `json:"list_id,omitempty"`
+	Messages int      `json:"messages"`
+	Unread   int      `json:"unread"`
+	OneClick []string `json:"one_click,omitempty"` // POST List-Unsubscribe=One-Click
+	URLs     []string `json:"urls,omitempty"`      // Open in a browser
+	Mailto   []string `json:"mailto,omitempty"`
+}
+
+// Plan builds plan entries for the chosen senders.
+func Plan(senders []Sender) []PlanEntry {
+	entries := make([]PlanEntry, 0, len(senders))
+	for _, s := range senders {
+		e := PlanEntry{
+			Sender:   s.Label(),
+			Address:  s.Address,
+			ListID:   s.ListID,
+			Messages: s.Count,
+			Unread:   s.Unread,
+			Mailto:   s.Mailto,
+		}
+		if s.OneClick {
+			e.OneClick = s.URLs
+		} else {
+			e.URLs = s.URLs
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// WriteJSON writes the plan as an indented JSON array.
+func WriteJSON(w io.Writer, entries []PlanEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// WriteText writes the plan as a checklist, one block per sender.
+func WriteText(w io.Writer, entries []PlanEntry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Unsubscribe plan — %s\n", time.Now().Format("2006-01-02"))
+	fmt.Fprintf(&b, "# %d senders. Cut the lines.\n", len(entries))
+
+	for _, e := range entries {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "[ ] %s <%s>\n", e.Sender, e.Address)
+		if e.ListID != "" {
+			fmt.Fprintf(&b, "    list:      %s\n", e.ListID)
+		}
+		fmt.Fprintf(&b, "    volume:    %d messages, %d unread\n", e.Messages, e.Unread)
+		for _, u := range e.OneClick {
+			fmt.Fprintf(&b, "    one-click: curl -X POST -d 'List-Unsubscribe=One-Click' %s\n", shellQuote(u))
+		}
+		for _, u := range e.URLs {
+			fmt.Fprintf(&b, "    open:      %s\n", u)
+		}
+		for _, m := range e.Mailto {
+			fmt.Fprintf(&b, "    mail:      %s\n", m)
+		}
+		if len(e.OneClick)+len(e.URLs)+len(e.Mailto) == 0 {
+			b.WriteString("    manual:    no List-Unsubscribe header; filter or block\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// shellQuote quotes s as one single-quoted shell word, so a URL pasted
+// from a header can't end the quote and run something of its own.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,40 @@
+package declutter
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestShellQuote(t *testing.T) {
+	for _, s := range []string{
+		"https://example.com/u?a=1&b=2",
+		"https://example.com/u';touch /tmp/pwned;'",
+		"https://example.com/$(id)`id`\"x\"",
+		"",
+	} {
+		out, err := exec.Command("sh", "-c", "printf %s "+shellQuote(s)).Output()
+		if err != nil {
+			t.Fatalf("sh on %q: %v", s, err)
+		}
+		if string(out) != s {
+			t.Errorf("sh read %q back as %q", s, out)
+		}
+	}
+}
+
+func TestWriteTextQuotesOneClick(t *testing.T) {
+	var b strings.Builder
+	err := WriteText(&b, []PlanEntry{{
+		Sender:   "Weekly",
+		Address:  "news@example.com",
+		OneClick: []string{"https://example.com/u'; rm -rf ~; '"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `curl -X POST -d 'List-Unsubscribe=One-Click' 'https://example.com/u'\''; rm -rf ~; '\'''`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("plan is\n%s\nwant a line with\n%s", b.String(), want)
+	}
+}
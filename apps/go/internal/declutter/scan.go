@@ -0,0 +1,223 @@
+package declutter
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Message is the subset of a mail message the triage needs.
+// Bodies are never read.
+type Message struct {
+	Address         string // Sender address, lower-cased
+	Name            string // Display name from From:
+	ListID          string // List-Id without angle brackets
+	Unsubscribe     string // Raw List-Unsubscribe header
+	UnsubscribePost string // Raw List-Unsubscribe-Post header
+	Read            bool
+	Date            time.Time
+}
+
+// Scan reads every mbox file and Maildir found under paths.
+// A path may be an mbox file, a Maildir, or a directory containing either.
+func Scan(paths []string) ([]Message, error) {
+	var msgs []Message
+	for _, p := range paths {
+		found, err := scanPath(p)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, found...)
+	}
+	return msgs, nil
+}
+
+func scanPath(path string) ([]Message, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return scanMbox(path)
+	}
+	if isMaildir(path) {
+		return scanMaildir(path)
+	}
+
+	var msgs []Message
+	err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && isMaildir(p) {
+				found, err := scanMaildir(p)
+				if err != nil {
+					return err
+				}
+				msgs = append(msgs, found...)
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isMbox(p) {
+			return nil
+		}
+		found, err := scanMbox(p)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, found...)
+		return nil
+	})
+	return msgs, err
+}
+
+func isMaildir(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, "cur"))
+	return err == nil && info.IsDir()
+}
+
+// isMbox sniffs the first line for the mbox "From " separator.
+func isMbox(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, 5)
+	n, _ := io.ReadFull(f, head)
+	return n == 5 && string(head) == "From "
+}
+
+// scanMaildir reads new/ (always unread) and cur/ (read when the
+// info suffix carries the S flag).
+func scanMaildir(dir string) ([]Message, error) {
+	var msgs []Message
+	for _, sub := range []string{"new", "cur"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				continue
+			}
+			f, err := os.Open(filepath.Join(dir, sub, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			msg, err := readHeaders(bufio.NewReader(f))
+			f.Close()
+			if err != nil {
+				continue // Skip unparseable files rather than abort the scan
+			}
+			msg.Read = sub == "cur" && maildirSeen(e.Name())
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+// maildirSeen reports whether a Maildir filename has the S (seen) flag.
+func maildirSeen(name string) bool {
+	i := strings.LastIndex(name, ":2,")
+	if i < 0 {
+		return false
+	}
+	return strings.ContainsRune(name[i+3:], 'S')
+}
+
+// scanMbox splits an mbox file on "From " separator lines and parses
+// each message's header block. Read state comes from the Status header.
+func scanMbox(path string) ([]Message, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var msgs []Message
+	var header bytes.Buffer
+	inHeader := false
+	prevBlank := true
+
+	flush := func() {
+		if header.Len() == 0 {
+			return
+		}
+		header.WriteString("\n")
+		if msg, err := readHeaders(bufio.NewReader(&header)); err == nil {
+			msgs = append(msgs, msg)
+		}
+		header.Reset()
+	}
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			trimmed := strings.TrimRight(line, "\r\n")
+			switch {
+			case prevBlank && strings.HasPrefix(line, "From "):
+				flush()
+				inHeader = true
+			case inHeader && trimmed == "":
+				inHeader = false
+				flush()
+			case inHeader:
+				header.WriteString(trimmed)
+				header.WriteString("\n")
+			}
+			prevBlank = trimmed == ""
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	flush()
+	return msgs, nil
+}
+
+func readHeaders(r *bufio.Reader) (Message, error) {
+	m, err := mail.ReadMessage(r)
+	if err != nil {
+		return Message{}, err
+	}
+	h := m.Header
+
+	// List-Id often carries a phrase before the id: "Weekly <weekly.example.com>"
+	listID := strings.TrimSpace(h.Get("List-Id"))
+	if i := strings.LastIndex(listID, "<"); i >= 0 {
+		listID = listID[i:]
+	}
+
+	msg := Message{
+		ListID:          strings.Trim(listID, "<>"),
+		Unsubscribe:     h.Get("List-Unsubscribe"),
+		UnsubscribePost: h.Get("List-Unsubscribe-Post"),
+		Read:            strings.ContainsRune(h.Get("Status"), 'R'),
+	}
+
+	if addr, err := mail.ParseAddress(h.Get("From")); err == nil {
+		msg.Address = strings.ToLower(addr.Address)
+		msg.Name = addr.Name
+	} else {
+		msg.Address = strings.ToLower(strings.TrimSpace(h.Get("From")))
+	}
+	if d, err := h.Date(); err == nil {
+		msg.Date = d
+	}
+	return msg, nil
+}
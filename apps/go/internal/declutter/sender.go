@@ -0,0 +1,156 @@
+package declutter
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Sender aggregates every message from one mailing list or address.
+type Sender struct {
+	Key      string // List-Id when present, otherwise the address
+	Name     string
+	Address  string
+	ListID   string
+	Count    int
+	Unread   int
+	Last     time.Time
+	Mailto   []string // mailto: targets from List-Unsubscribe
+	URLs     []string // http(s) targets from List-Unsubscribe
+	OneClick bool     // RFC 8058 List-Unsubscribe-Post present
+}
+
+// UnreadRatio is the share of messages never opened, 0..1.
+func (s Sender) UnreadRatio() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Unread) / float64(s.Count)
+}
+
+// CanUnsubscribe reports whether the sender advertised any way out.
+func (s Sender) CanUnsubscribe() bool {
+	return len(s.Mailto) > 0 || len(s.URLs) > 0
+}
+
+// Label is the human-facing name for the sender.
+func (s Sender) Label() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.ListID != "":
+		return s.ListID
+	default:
+		return s.Address
+	}
+}
+
+// SortOrder selects how Group ranks senders.
+type SortOrder int
+
+const (
+	SortVolume SortOrder = iota // Most messages first
+	SortUnread                  // Most ignored first
+)
+
+// Group folds messages into senders keyed by List-Id, falling back to
+// the From address, and ranks them by the given order.
+func Group(msgs []Message, order SortOrder) []Sender {
+	index := make(map[string]int)
+	var senders []Sender
+
+	for _, msg := range msgs {
+		key := msg.ListID
+		if key == "" {
+			key = msg.Address
+		}
+		if key == "" {
+			continue
+		}
+
+		i, ok := index[key]
+		if !ok {
+			i = len(senders)
+			index[key] = i
+			senders = append(senders, Sender{
+				Key:     key,
+				Address: msg.Address,
+				ListID:  msg.ListID,
+			})
+		}
+		s := &senders[i]
+		s.Count++
+		if !msg.Read {
+			s.Unread++
+		}
+		// Latest message wins for display name and unsubscribe targets,
+		// since lists rotate their links.
+		if msg.Date.IsZero() || !msg.Date.Before(s.Last) {
+			if !msg.Date.IsZero() {
+				s.Last = msg.Date
+			}
+			if msg.Name != "" {
+				s.Name = msg.Name
+			}
+			if msg.Unsubscribe != "" {
+				s.Mailto, s.URLs = parseUnsubscribe(msg.Unsubscribe)
+				s.OneClick = isOneClick(msg.UnsubscribePost) && len(s.URLs) > 0
+			}
+		}
+	}
+
+	Sort(senders, order)
+	return senders
+}
+
+// Sort ranks senders in place.
+func Sort(senders []Sender, order SortOrder) {
+	sort.SliceStable(senders, func(i, j int) bool {
+		a, b := senders[i], senders[j]
+		if order == SortUnread {
+			if a.UnreadRatio() != b.UnreadRatio() {
+				return a.UnreadRatio() > b.UnreadRatio()
+			}
+			return a.Count > b.Count
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Unread > b.Unread
+	})
+}
+
+// parseUnsubscribe splits a List-Unsubscribe header (RFC 2369) into
+// mailto and http(s) targets. Each target is wrapped in angle brackets.
+// Targets with control characters are dropped rather than printed.
+func parseUnsubscribe(header string) (mailto, urls []string) {
+	for {
+		start := strings.IndexByte(header, '<')
+		if start < 0 {
+			return
+		}
+		end := strings.IndexByte(header[start:], '>')
+		if end < 0 {
+			return
+		}
+		target := strings.TrimSpace(header[start+1 : start+end])
+		header = header[start+end+1:]
+		if strings.IndexFunc(target, unicode.IsControl) >= 0 {
+			continue
+		}
+
+		lower := strings.ToLower(target)
+		switch {
+		case strings.HasPrefix(lower, "mailto:"):
+			mailto = append(mailto, target)
+		case strings.HasPrefix(lower, "https://"), strings.HasPrefix(lower, "http://"):
+			urls = append(urls, target)
+		}
+	}
+}
+
+// isOneClick checks for the RFC 8058 one-click POST marker.
+func isOneClick(header string) bool {
+	return strings.EqualFold(strings.ReplaceAll(header, " ", ""), "List-Unsubscribe=One-Click")
+}
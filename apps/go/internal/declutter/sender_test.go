@@ -0,0 +1,69 @@
+package declutter
+
+import (
+	"bufio"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseUnsubscribe(t *testing.T) {
+	tests := []struct {
+		header string
+		mailto []string
+		urls   []string
+	}{
+		{"", nil, nil},
+		{"<mailto:leave@example.com?subject=stop>", []string{"mailto:leave@example.com?subject=stop"}, nil},
+		{"<https://example.com/u/1>, <mailto:u@example.com>", []string{"mailto:u@example.com"}, []string{"https://example.com/u/1"}},
+		{"< HTTP://example.com/u >", nil, []string{"HTTP://example.com/u"}},
+		{"<ftp://example.com/u>, <https://example.com/u", nil, nil},
+		{"<https://example.com/\x1b]0;x\x07>", nil, nil},
+	}
+	for _, tt := range tests {
+		mailto, urls := parseUnsubscribe(tt.header)
+		if !slices.Equal(mailto, tt.mailto) || !slices.Equal(urls, tt.urls) {
+			t.Errorf("parseUnsubscribe(%q) = %q, %q; want %q, %q", tt.header, mailto, urls, tt.mailto, tt.urls)
+		}
+	}
+}
+
+func TestIsOneClick(t *testing.T) {
+	for header, want := range map[string]bool{
+		"List-Unsubscribe=One-Click":   true,
+		"list-unsubscribe = one-click": true,
+		"":                             false,
+		"List-Unsubscribe=Two-Click":   false,
+	} {
+		if got := isOneClick(header); got != want {
+			t.Errorf("isOneClick(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestReadHeaders(t *testing.T) {
+	raw := "From: Weekly News <News@Example.com>\r\n" +
+		"List-Id: Weekly <weekly.example.com>\r\n" +
+		"List-Unsubscribe: <https://example.com/u>,\r\n <mailto:u@example.com>\r\n" +
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n" +
+		"Status: RO\r\n" +
+		"\r\n" +
+		"body\r\n"
+	m, err := readHeaders(bufio.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Address != "news@example.com" || m.Name != "Weekly News" {
+		t.Errorf("from = %q <%s>, want \"Weekly News\" <news@example.com>", m.Name, m.Address)
+	}
+	if m.ListID != "weekly.example.com" {
+		t.Errorf("list id = %q, want weekly.example.com", m.ListID)
+	}
+	if !m.Read {
+		t.Error("Status RO read as unread")
+	}
+	s := Group([]Message{m}, SortVolume)[0]
+	if !slices.Equal(s.URLs, []string{"https://example.com/u"}) || !slices.Equal(s.Mailto, []string{"mailto:u@example.com"}) || !s.OneClick {
+		t.Errorf("sender = %+v, want one one-click URL and one mailto", s)
+	}
+}
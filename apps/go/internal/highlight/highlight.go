@@ -0,0 +1,188 @@
+// Package highlight keeps the lines of the invocation a practitioner
+// marked to come back to, each with an optional note.
+package highlight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Highlight is a marked line, or a whole marked section.
+type Highlight struct {
+	Section string    `json:"section"` // Title of the section
+	Number  int       `json:"number"`  // The section's number, 1-based, for reading order
+	Line    int       `json:"line"`    // Body line; -1 marks the whole section
+	Text    string    `json:"text"`    // The line as read, or the key line for a section
+	Note    string    `json:"note,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Whole reports whether h marks its whole section.
+func (h Highlight) Whole() bool {
+	return h.Line < 0
+}
+
+// Store is the practitioner's highlights, kept as a JSON list at path
+// and rewritten whole on every change.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	items []Highlight
+}
+
+// Open reads the highlights at path. A missing file is none yet.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.items); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Dir is the directory the highlights are kept in.
+func (s *Store) Dir() string {
+	return filepath.Dir(s.path)
+}
+
+// All returns the highlights in reading order. A nil Store has none.
+func (s *Store) All() []Highlight {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := slices.Clone(s.items)
+	slices.SortStableFunc(out, func(a, b Highlight) int {
+		if a.Number != b.Number {
+			return a.Number - b.Number
+		}
+		return a.Line - b.Line
+	})
+	return out
+}
+
+// Has reports whether line of the section titled section is marked;
+// line -1 asks about the whole section.
+func (s *Store) Has(section string, line int) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.find(section, line) >= 0
+}
+
+// Add marks h's place, replacing any highlight already there.
+func (s *Store) Add(h Highlight) error {
+	if h.Time.IsZero() {
+		h.Time = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := slices.Clone(s.items)
+	if i := s.find(h.Section, h.Line); i >= 0 {
+		items[i] = h
+	} else {
+		items = append(items, h)
+	}
+	return s.save(items)
+}
+
+// Remove unmarks a place. Removing one never marked is no error.
+func (s *Store) Remove(section string, line int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.find(section, line)
+	if i < 0 {
+		return nil
+	}
+	return s.save(slices.Delete(slices.Clone(s.items), i, i+1))
+}
+
+func (s *Store) find(section string, line int) int {
+	return slices.IndexFunc(s.items, func(h Highlight) bool {
+		return h.Section == section && h.Line == line
+	})
+}
+
+// save writes items and, once they are on disk, keeps them. Each save
+// writes its own temporary file, so two never interleave in one.
+func (s *Store) save(items []Highlight) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	s.items = items
+	return nil
+}
+
+// ExportMarkdown writes highlights grouped by section, in reading
+// order, each quoted with its note beneath.
+func ExportMarkdown(w io.Writer, hs []Highlight) error {
+	var b strings.Builder
+	b.WriteString("# Highlights\n")
+	number := 0
+	for _, h := range hs {
+		if h.Number != number {
+			number = h.Number
+			fmt.Fprintf(&b, "\n## %d. %s\n", h.Number, h.Section)
+		}
+		b.WriteString("\n> ")
+		b.WriteString(h.Text)
+		if h.Whole() {
+			b.WriteString(" *(the whole section)*")
+		}
+		b.WriteString("\n")
+		if h.Note != "" {
+			b.WriteString("\n")
+			b.WriteString(h.Note)
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "\n*%s*\n", h.Time.Local().Format("2006-01-02 15:04"))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ExportJSON writes highlights as an indented JSON list.
+func ExportJSON(w io.Writer, hs []Highlight) error {
+	if hs == nil {
+		hs = []Highlight{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(hs)
+}
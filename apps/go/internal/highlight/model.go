@@ -0,0 +1,277 @@
+package highlight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// OpenMsg asks for the invocation opened at a highlight: section, and
+// the body line to show, -1 for the section's head.
+type OpenMsg struct {
+	Section int
+	Line    int
+}
+
+// Model is the highlights view: marked lines grouped by section, to
+// revisit, remove or export as markdown.
+type Model struct {
+	store    *Store
+	shown    []Highlight // The store's highlights as last read
+	cursor   int
+	offset   int
+	status   string
+	keys     keys.Map
+	help     help.Model
+	theme    theme.Theme
+	width    int
+	height   int
+	ready    bool
+	renderer *lipgloss.Renderer
+}
+
+func New(r *lipgloss.Renderer, s *Store, th theme.Theme, km keys.Map) Model {
+	return Model{
+		store:    s,
+		shown:    s.All(),
+		keys:     km.Or(),
+		help:     keys.NewHelp(r, th),
+		theme:    th,
+		renderer: r,
+	}
+}
+
+// Capturing reports false: the view takes no text.
+func (m Model) Capturing() bool {
+	return false
+}
+
+// Resume picks up lines marked since the view was last shown.
+func (m Model) Resume() (tea.Model, tea.Cmd) {
+	m.shown = m.store.All()
+	m.moveCursor(0)
+	return m, nil
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case theme.ChangedMsg:
+		m.theme = msg.Theme
+		m.help = keys.NewHelp(m.renderer, m.theme)
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.moveCursor(0)
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.moveCursor(-1)
+		case tea.MouseButtonWheelDown:
+			m.moveCursor(1)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		m.status = ""
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, m.keys.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, m.keys.Select):
+			if m.cursor < len(m.shown) {
+				h := m.shown[m.cursor]
+				return m, func() tea.Msg {
+					return OpenMsg{Section: h.Number - 1, Line: h.Line}
+				}
+			}
+		case key.Matches(msg, m.keys.Mark), key.Matches(msg, m.keys.MarkSection):
+			if m.cursor < len(m.shown) {
+				h := m.shown[m.cursor]
+				if err := m.store.Remove(h.Section, h.Line); err != nil {
+					m.status = "highlights: " + err.Error()
+				} else {
+					m.status = "Highlight removed."
+				}
+				m.shown = m.store.All()
+				m.moveCursor(0)
+			}
+		case key.Matches(msg, m.keys.Export):
+			path, err := m.export()
+			if err != nil {
+				m.status = "export: " + err.Error()
+			} else {
+				m.status = "Exported to " + path
+			}
+		}
+	}
+	return m, nil
+}
+
+// moveCursor moves the selection by delta, scrolling to keep it shown.
+func (m *Model) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.shown)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	for m.offset < m.cursor && m.rows(m.offset, m.cursor+1) > m.listHeight() {
+		m.offset++
+	}
+}
+
+// rows is how many lines highlights from to to take in the list: each
+// its text and any note, and a heading wherever the section changes.
+func (m Model) rows(from, to int) int {
+	n := 0
+	for i := from; i < to && i < len(m.shown); i++ {
+		if i == from || m.shown[i].Number != m.shown[i-1].Number {
+			n += 2
+		}
+		n++
+		if m.shown[i].Note != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// export writes the highlights as markdown beside where they are kept.
+func (m Model) export() (string, error) {
+	path := filepath.Join(m.store.Dir(), "highlights-"+time.Now().Format("2006-01-02")+".md")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := ExportMarkdown(f, m.shown); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+func (m Model) contentWidth() int {
+	_, x := m.padding()
+	return max(10, min(m.width-2*x, 72))
+}
+
+func (m Model) listHeight() int {
+	y, _ := m.padding()
+	return max(3, m.height-6-2*y)
+}
+
+// padding is the space around the view, dropped to a column on a
+// narrow or short terminal.
+func (m Model) padding() (y, x int) {
+	if layout.Narrow(m.width) || layout.Short(m.height) {
+		return 0, 1
+	}
+	return 1, 2
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	th := m.theme
+	quiet := theme.Degraded(r)
+	titleStyle := r.NewStyle().Foreground(th.Accent).Bold(true)
+	headStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+	sectionStyle := r.NewStyle().Foreground(th.Title).Bold(true)
+	textStyle := r.NewStyle().Foreground(th.Text)
+	cursorStyle := r.NewStyle().Foreground(th.Bright).Bold(true)
+	noteStyle := r.NewStyle().Foreground(th.Faded).Faint(quiet).Italic(true)
+	markStyle := r.NewStyle().Foreground(th.Highlight)
+
+	w := m.contentWidth()
+	help := m.help
+	help.Width = w
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("॥ HIGHLIGHTS ॥"))
+	b.WriteString(headStyle.Render(fmt.Sprintf("  %d kept", len(m.shown))))
+	b.WriteString("\n\n")
+
+	if len(m.shown) == 0 {
+		b.WriteString(textStyle.Width(w).Render("Nothing highlighted yet. While reading, press " + m.keys.Mark.Help().Key + " to keep a line, or " + m.keys.MarkSection.Help().Key + " for the whole section."))
+		b.WriteString("\n")
+	}
+
+	h := m.listHeight()
+	for i := m.offset; i < len(m.shown); i++ {
+		hl := m.shown[i]
+		if m.rows(m.offset, i+1) > h {
+			break
+		}
+		if i == m.offset || hl.Number != m.shown[i-1].Number {
+			if i > m.offset {
+				b.WriteString("\n")
+			}
+			b.WriteString(sectionStyle.Render(truncate(fmt.Sprintf("%d. %s", hl.Number, hl.Section), w)))
+			b.WriteString("\n")
+			if i == m.offset {
+				b.WriteString("\n")
+			}
+		}
+		text := hl.Text
+		if hl.Whole() {
+			text += " (the whole section)"
+		}
+		text = truncate(text, w-2)
+		if i == m.cursor {
+			b.WriteString(markStyle.Render("▍") + " " + cursorStyle.Render(text))
+		} else {
+			b.WriteString(markStyle.Render("▏") + " " + textStyle.Render(text))
+		}
+		b.WriteString("\n")
+		if hl.Note != "" {
+			b.WriteString("  " + noteStyle.Render(truncate(hl.Note, w-2)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	if m.status != "" {
+		b.WriteString(noteStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(help.ShortHelpView(m.keys.HighlightsView().ShortHelp()))
+
+	return r.NewStyle().Padding(m.padding()).Render(b.String())
+}
+
+// truncate cuts s to w characters, ending in an ellipsis when cut.
+func truncate(s string, w int) string {
+	if w < 1 || len([]rune(s)) <= w {
+		return s
+	}
+	return string([]rune(s)[:w-1]) + "…"
+}
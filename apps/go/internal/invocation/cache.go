@@ -0,0 +1,94 @@
+package invocation
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/layout"
+)
+
+// renderCache keeps what the reveal draws again and again: body lines
+// wrapped, styled and centred at each opacity, and the chrome around
+// the page. Every copy of a Model shares one, so a tick only renders
+// the lines whose opacity moved. A resize or theme change empties it.
+type renderCache struct {
+	width int  // Page width the entries were rendered at
+	short bool // Whether they were spaced for a short terminal
+	lines map[lineKey][]string
+	heads map[int][]string // Title and key line by section, once typed
+	blank string
+	thumb string // Scrollbar glyphs
+	track string
+	hint  string            // The key hint, centred
+	mark  string            // The highlight marker
+	marks map[string]string // Scroll indicators and status bar lines by their text
+}
+
+// lineKey is one body line of one section at one opacity.
+type lineKey struct {
+	section, line, opacity int
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{}
+}
+
+// fit empties the cache when the page width, or which side of the
+// short breakpoint the height is on, has changed.
+func (c *renderCache) fit(width int, short bool) {
+	if c.width != width || c.short != short || c.lines == nil {
+		c.reset()
+		c.width, c.short = width, short
+		c.blank = strings.Repeat(" ", width)
+	}
+}
+
+// reset empties the cache, as when the theme changes.
+func (c *renderCache) reset() {
+	c.lines = make(map[lineKey][]string)
+	c.marks = make(map[string]string)
+	c.heads = make(map[int][]string)
+	c.width = -1
+	c.thumb, c.track, c.hint, c.mark = "", "", "", ""
+}
+
+// fitCache is the render cache, emptied first if the page has changed
+// since it was filled.
+func (m Model) fitCache() *renderCache {
+	m.cache.fit(m.pageWidth(), layout.Short(m.height))
+	return m.cache
+}
+
+// bodyLine is body line i of the current section at opacity, centred
+// on the page, rendering it on first use.
+func (m Model) bodyLine(i, opacity int) []string {
+	c := m.fitCache()
+	k := lineKey{m.sectionIndex, i, opacity}
+	if lines, ok := c.lines[k]; ok {
+		return lines
+	}
+	lines := m.centre(m.renderLine(sections[m.sectionIndex].Lines[i], opacity))
+	c.lines[k] = lines
+	return lines
+}
+
+// centre splits text into lines, each centred and padded to the page
+// width.
+func (m Model) centre(text string) []string {
+	c := m.fitCache()
+	w := m.pageWidth()
+	parts := strings.Split(text, "\n")
+	lines := make([]string, 0, len(parts))
+	for _, line := range parts {
+		if line == "" {
+			lines = append(lines, c.blank)
+			continue
+		}
+		n := lipgloss.Width(line)
+		left := max(0, (w-n)/2)
+		right := max(0, w-left-n)
+		lines = append(lines, strings.Repeat(" ", left)+line+strings.Repeat(" ", right))
+	}
+	return lines
+}
@@ -0,0 +1,145 @@
+package invocation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Title names this part of the manifesto.
+const Title = "Part I: The Invocation"
+
+// Sections returns the invocation's content, for readers outside the TUI.
+func Sections() []Section {
+	return append([]Section(nil), sections...)
+}
+
+// WriteMarkdown writes sections in the manifesto's markdown form: a
+// heading per section, its key line as a quote, then the body, with
+// notes renumbered as footnotes at the end.
+func WriteMarkdown(w io.Writer, sections []Section) error {
+	notes := Endnotes(sections)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", Title)
+	for i, s := range sections {
+		fmt.Fprintf(&b, "\n## %s\n\n> %s\n\n", s.Title, s.KeyLine)
+		for _, line := range s.Lines {
+			if line == "" {
+				b.WriteString("\n")
+				continue
+			}
+			line = ReplaceNoteRefs(line, func(id string) string {
+				if n := EndnoteNumber(notes, i, id); n > 0 {
+					return fmt.Sprintf("[^%d]", n)
+				}
+				return ""
+			})
+			// Trailing double space keeps the verse's line breaks
+			b.WriteString(line + "  \n")
+		}
+	}
+	if len(notes) > 0 {
+		b.WriteString("\n---\n\n")
+	}
+	for _, n := range notes {
+		fmt.Fprintf(&b, "[^%d]: %s\n", n.N, n.Text)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// TextOptions typesets sections for a pipe, a pager or a printer.
+type TextOptions struct {
+	Width    int                // Wrap to this many columns
+	Renderer *lipgloss.Renderer // Decides the colour; an Ascii profile writes none
+	Theme    theme.Theme
+}
+
+// WriteText writes sections as typeset text: a heading and key line per
+// section, then the verse wrapped to Width, **bold** kept as emphasis
+// where the renderer has colour and dropped where it has none. Notes
+// are numbered [1], [2]... and listed at the end.
+func WriteText(w io.Writer, sections []Section, opts TextOptions) error {
+	if opts.Width < minTextWidth {
+		opts.Width = minTextWidth
+	}
+	if opts.Theme.Name == "" {
+		opts.Theme = theme.Default()
+	}
+	s := NewStyles(opts.Renderer, opts.Theme)
+
+	notes := Endnotes(sections)
+	var b strings.Builder
+	b.WriteString(s.KeyLine.Render("॥ CYBERTANTRA ॥") + "\n")
+	b.WriteString(s.Dim.Render(Title) + "\n")
+	for i, sec := range sections {
+		b.WriteString("\n\n" + s.Title.Render(sec.Title) + "\n")
+		b.WriteString(s.Dim.Render(strings.Repeat("─", lipgloss.Width(sec.Title))) + "\n\n")
+		for _, line := range layout.Wrap(sec.KeyLine, opts.Width) {
+			b.WriteString(s.KeyLine.Render(line) + "\n")
+		}
+		b.WriteString("\n")
+		for _, line := range sec.Lines {
+			line = ReplaceNoteRefs(line, func(id string) string {
+				if n := EndnoteNumber(notes, i, id); n > 0 {
+					return fmt.Sprintf("[%d]", n)
+				}
+				return ""
+			})
+			for _, l := range typeset(line, opts.Width, s.Body, s.Bold) {
+				b.WriteString(l + "\n")
+			}
+		}
+	}
+	if len(notes) > 0 {
+		b.WriteString("\n\n" + s.Title.Render("Notes") + "\n\n")
+	}
+	for _, n := range notes {
+		text := ReplaceLinks(n.Text, func(label, url string) string {
+			return label + " <" + url + ">"
+		})
+		for _, l := range typeset(fmt.Sprintf("[%d] %s", n.N, text), opts.Width, s.Dim, s.Dim) {
+			b.WriteString(l + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// minTextWidth keeps the verse readable however narrow the request.
+const minTextWidth = 20
+
+// typeset wraps a body line to width, measuring words without their
+// ** markers, then styles each wrapped line with bold carried across
+// the breaks.
+func typeset(line string, width int, body, bold lipgloss.Style) []string {
+	if line == "" {
+		return []string{""}
+	}
+	wrapped := layout.Wrap(line, width)
+	out := make([]string, 0, len(wrapped))
+	inBold := false
+	for _, w := range wrapped {
+		var l strings.Builder
+		for i, part := range strings.Split(w, "**") {
+			if i > 0 {
+				inBold = !inBold
+			}
+			if part == "" {
+				continue
+			}
+			if inBold {
+				l.WriteString(bold.Render(part))
+			} else {
+				l.WriteString(body.Render(part))
+			}
+		}
+		out = append(out, l.String())
+	}
+	return out
+}
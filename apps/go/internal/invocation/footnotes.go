@@ -0,0 +1,84 @@
+package invocation
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cited is the notes the current section has cited so far, in the
+// order their markers appeared.
+func (m Model) cited() []Endnote {
+	switch m.phase {
+	case phaseTitleReveal, phaseKeyLineTyping, phaseBodyReveal, phaseWaitingForNext:
+	default:
+		return nil
+	}
+	lines := sections[m.sectionIndex].Lines
+	var out []Endnote
+	for _, n := range endnotes() {
+		if n.Section != m.sectionIndex {
+			continue
+		}
+		for _, line := range lines[:min(m.lineIndex, len(lines))] {
+			if strings.Contains(line, "[^"+n.ID+"]") {
+				out = append(out, n)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// openFootnotes shows the notes cited so far over the page, or says
+// there are none yet.
+func (m Model) openFootnotes() (tea.Model, tea.Cmd) {
+	if len(m.cited()) == 0 {
+		m.status = "No notes in this section yet."
+		return m, nil
+	}
+	m.footnotes = true
+	return m, nil
+}
+
+// updateFootnotes closes the notes on any key; ctrl+c still quits.
+func (m Model) updateFootnotes(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.footnotes = false
+	if msg.Type == tea.KeyCtrlC {
+		return m.quit(msg)
+	}
+	return m, nil
+}
+
+// viewFootnotes is the section's notes, each after its marker, over
+// the page.
+func (m Model) viewFootnotes() string {
+	s := m.styles
+	w := max(10, min(m.width-8, 60))
+	var b strings.Builder
+	b.WriteString(s.KeyLine.Render("Notes"))
+	b.WriteString(s.Dim.Render(" · " + sections[m.sectionIndex].Title))
+	for _, n := range m.cited() {
+		b.WriteString("\n\n")
+		mark := Superscript(n.N) + " "
+		indent := strings.Repeat(" ", lipgloss.Width(mark))
+		for i, line := range WrapLinks(n.Text, w-len(indent), m.links) {
+			if i > 0 {
+				b.WriteString("\n" + indent)
+			} else {
+				b.WriteString(s.Bold.Render(mark))
+			}
+			b.WriteString(s.Body.Render(line))
+		}
+	}
+	b.WriteString("\n\n")
+	b.WriteString(s.Prompt.Render("any key to close"))
+
+	box := s.Body.
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Dim).
+		Padding(1, 2).
+		Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,161 @@
+package invocation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/layout"
+)
+
+// part names the invocation among the manifesto's parts, for the HUD.
+const part = "Part I"
+
+// hudRows is the rows the status bar takes: a header with the section
+// and a footer with the progress bar, folded into the header alone on
+// a short terminal. The opening, closing and exit screen go without.
+func (m Model) hudRows() int {
+	if !m.hud {
+		return 0
+	}
+	switch m.phase {
+	case phaseOpening, phaseClosing, phaseFarewell:
+		return 0
+	}
+	if layout.Short(m.height) {
+		return 1
+	}
+	return 2
+}
+
+// hudHeader is the current title on the left and the position on the
+// right, shrinking to fit w. Rendered headers are cached by their text.
+func (m Model) hudHeader(w int) string {
+	s := m.styles
+	pos := fmt.Sprintf("%d/%d", m.sectionIndex+1, len(sections))
+	right := part + " · Section " + pos
+	switch {
+	case m.hudRows() == 1:
+		right = pos + " · " + m.hudProgress()
+	case layout.Narrow(w):
+		right = pos
+	}
+	title := sections[m.sectionIndex].Title
+	k := "head\x00" + title + "\x00" + right
+	c := m.fitCache()
+	if line, ok := c.marks[k]; ok {
+		return line
+	}
+	left := truncate(title, w-lipgloss.Width(right)-3)
+	gap := max(1, w-2-lipgloss.Width(left)-lipgloss.Width(right))
+	line := " " + s.Title.Render(left) + strings.Repeat(" ", gap) + s.Dim.Render(right) + " "
+	c.marks[k] = line
+	return line
+}
+
+// hudFooter is a bar across the whole invocation with the share read
+// and the time left, the bar dropped when w is too narrow for it.
+// Rendered footers are cached by their label and how far the bar is.
+func (m Model) hudFooter(w int) string {
+	s := m.styles
+	label := " " + m.hudProgress()
+	bar := w - 2 - lipgloss.Width(label)
+	done := int(m.fraction()*float64(max(bar, 0)) + 0.5)
+	k := "foot\x00" + label + "\x00" + strconv.Itoa(done)
+	c := m.fitCache()
+	if line, ok := c.marks[k]; ok {
+		return line
+	}
+	line := lipgloss.PlaceHorizontal(w, lipgloss.Center, s.Dim.Render(strings.TrimSpace(label)))
+	if bar >= 8 {
+		line = " " + s.KeyLine.Render(strings.Repeat("━", done)) +
+			s.Dim.Render(strings.Repeat("─", bar-done)+label) + " "
+	}
+	c.marks[k] = line
+	return line
+}
+
+// hudProgress is the share of the invocation revealed, and about how
+// long the rest takes.
+func (m Model) hudProgress() string {
+	return fmt.Sprintf("%d%% · %s", int(m.fraction()*100), formatRemaining(m.remaining()))
+}
+
+// fraction is the share of all body lines revealed so far.
+func (m Model) fraction() float64 {
+	total, done := 0, 0
+	for i, s := range sections {
+		total += len(s.Lines)
+		switch {
+		case i < m.sectionIndex:
+			done += len(s.Lines)
+		case i == m.sectionIndex:
+			done += min(m.lineIndex, len(s.Lines))
+		}
+	}
+	if m.phase == phaseReflection {
+		done += len(sections[m.sectionIndex].Lines) - min(m.lineIndex, len(sections[m.sectionIndex].Lines))
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(done) / float64(total)
+}
+
+// remaining estimates the reveal still to come at the reader's pace:
+// the rest of this section, then every section after it.
+func (m Model) remaining() time.Duration {
+	var d time.Duration
+	for i := m.sectionIndex; i < len(sections); i++ {
+		s := sections[i]
+		typed, from := 0, 0 // Key line characters typed, body lines revealed
+		if i == m.sectionIndex {
+			switch m.phase {
+			case phaseTitleReveal:
+				typed = m.charIndex
+			case phaseKeyLineTyping:
+				typed = len(s.KeyLine)
+			case phaseBodyReveal:
+				typed, from = len(s.KeyLine), m.lineIndex
+			default:
+				continue // Revealed already
+			}
+		}
+		if from == 0 {
+			d += time.Duration(len(s.KeyLine)-typed)*typeDelay + keyLinePause
+		}
+		for j := min(from, len(s.Lines)); j < len(s.Lines); j++ {
+			d += m.pace.lineDelay(i, j)
+		}
+	}
+	return d
+}
+
+// formatRemaining writes a duration the way the HUD shows it.
+func formatRemaining(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "done"
+	case d < time.Minute:
+		return "<1 min left"
+	}
+	return fmt.Sprintf("~%d min left", int((d+time.Minute-1)/time.Minute))
+}
+
+// truncate shortens s to w cells, marking the cut with an ellipsis.
+func truncate(s string, w int) string {
+	if lipgloss.Width(s) <= w {
+		return s
+	}
+	if w <= 1 {
+		return ""
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > w {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
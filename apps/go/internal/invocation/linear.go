@@ -0,0 +1,333 @@
+package invocation
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorkolas/cybertantra/internal/highlight"
+	"github.com/gorkolas/cybertantra/internal/journal"
+)
+
+// AccessibleEnvVar asks for the accessible reader instead of the full
+// screen one: 1/on/true.
+const AccessibleEnvVar = "CYBERTANTRA_ACCESSIBLE"
+
+// Accessible reports whether an AccessibleEnvVar value turns it on.
+func Accessible(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "on", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// linearHelp lists the commands at the accessible reader's prompt.
+const linearHelp = "Commands: enter for the next section, b for the previous, r to repeat, c for contents, a section number to go there, m to highlight the section, with a note after the m if you like, h for help, q to quit."
+
+// ReadLinear reads the invocation for screen readers and braille
+// displays: no alternate screen, no animation and no colour. Each line
+// is written once, as plain text in reading order, with headings and
+// notes marked in words, and the practitioner moves on by typing a
+// command and pressing enter. Progress and reflections are kept as in
+// the full reader. It starts at section (1-based), or where the last
+// reading left off when section is 0.
+func ReadLinear(in io.Reader, out io.Writer, section int, opts Options) error {
+	r := &linear{w: bufio.NewWriter(out), opts: opts, notes: Endnotes(sections)}
+	if opts.Echo {
+		r.raw = bufio.NewReader(in)
+	} else {
+		r.sc = bufio.NewScanner(in)
+		r.sc.Split(r.scanLines)
+	}
+
+	r.printf("Cybertantra. %s. %d sections.\n", Title, len(sections))
+	r.printf("%s\n", linearHelp)
+	i := max(0, min(section, len(sections))-1)
+	if p, ok := LoadProgress(opts.Progress); ok && section == 0 {
+		i = p.sectionIndex()
+		r.printf("You left off at section %d, %s. Press enter to resume there, or type s to start from the beginning.\n", i+1, p.Section)
+		answer, ok := r.ask("Resume:")
+		if !ok {
+			return r.w.Flush()
+		}
+		if answer == "s" {
+			i = 0
+		}
+	}
+
+	for {
+		r.section(i)
+		r.save(i)
+		if !r.reflect(i) {
+			return r.leave(i)
+		}
+		next, ok := r.command(i)
+		if !ok {
+			return r.leave(i)
+		}
+		if next == len(sections) {
+			r.save(next)
+			r.printf("\nThe invocation is complete. Om. You are a god in training.\n")
+			return r.w.Flush()
+		}
+		i = next
+	}
+}
+
+// linear is the state of one accessible reading.
+type linear struct {
+	sc    *bufio.Scanner
+	raw   *bufio.Reader // In place of sc when the reader does its own echo
+	w     *bufio.Writer
+	opts  Options
+	notes []Endnote
+	cr    bool // The last line ended in a carriage return
+}
+
+func (r *linear) printf(format string, a ...any) {
+	fmt.Fprintf(r.w, format, a...)
+}
+
+// ask writes a prompt and reads the answer, trimmed and lowercased.
+// It reports false at the end of input.
+func (r *linear) ask(prompt string) (string, bool) {
+	line, ok := r.askRaw(prompt)
+	return strings.ToLower(strings.TrimSpace(line)), ok
+}
+
+// askRaw is ask keeping what was typed as it was.
+func (r *linear) askRaw(prompt string) (string, bool) {
+	r.printf("%s ", prompt)
+	r.w.Flush()
+	if r.raw != nil {
+		return r.readEcho()
+	}
+	if !r.sc.Scan() {
+		return "", false
+	}
+	return r.sc.Text(), true
+}
+
+// readEcho reads a line from a terminal in raw mode, where nothing is
+// echoed unless the reader does it: typed characters are written back,
+// backspace and delete take the last one away, ctrl+u clears the line
+// and escape sequences, such as the arrow keys, are dropped. ctrl+c,
+// or ctrl+d on an empty line, ends the input.
+func (r *linear) readEcho() (string, bool) {
+	var line []rune
+	defer r.w.Flush()
+	for {
+		r.w.Flush()
+		c, _, err := r.raw.ReadRune()
+		if err != nil {
+			return string(line), len(line) > 0
+		}
+		switch {
+		case c == '\r' || c == '\n':
+			if c == '\r' {
+				if next, err := r.raw.Peek(1); err == nil && next[0] == '\n' {
+					r.raw.ReadByte()
+				}
+			}
+			r.printf("\n")
+			return string(line), true
+		case c == 0x7f || c == '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				r.printf("\b \b")
+			}
+		case c == 0x15: // ctrl+u
+			r.printf("%s", strings.Repeat("\b \b", len(line)))
+			line = line[:0]
+		case c == 0x03, c == 0x04 && len(line) == 0:
+			r.printf("\n")
+			return "", false
+		case c == 0x1b:
+			r.skipEscape()
+		case c >= 0x20:
+			line = append(line, c)
+			r.printf("%c", c)
+		}
+	}
+}
+
+// skipEscape drops the rest of an escape sequence: ESC [ or ESC O,
+// parameters, and a final letter.
+func (r *linear) skipEscape() {
+	b, err := r.raw.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return
+	}
+	for {
+		b, err := r.raw.ReadByte()
+		if err != nil || (b >= 0x40 && b <= 0x7e) {
+			return
+		}
+	}
+}
+
+// section writes section i: a heading, the key line, the verse with
+// emphasis markers dropped and note references in words, then its
+// notes.
+func (r *linear) section(i int) {
+	s := sections[i]
+	r.printf("\nHeading: Section %d of %d, %s.\n", i+1, len(sections), s.Title)
+	if r.opts.Highlights.Has(s.Title, -1) {
+		r.printf("You highlighted this section.\n")
+	}
+	r.printf("Key line: %s\n\n", StripNoteRefs(strings.ReplaceAll(s.KeyLine, "**", "")))
+	for k, line := range s.Lines {
+		line = ReplaceNoteRefs(strings.ReplaceAll(line, "**", ""), func(id string) string {
+			if n := EndnoteNumber(r.notes, i, id); n > 0 {
+				return fmt.Sprintf(" (note %d)", n)
+			}
+			return ""
+		})
+		if line != "" && r.opts.Highlights.Has(s.Title, k) {
+			line += " (highlighted)"
+		}
+		r.printf("%s\n", line)
+	}
+	for _, n := range r.notes {
+		if n.Section != i {
+			continue
+		}
+		text := ReplaceLinks(n.Text, func(label, url string) string {
+			return label + ", link " + url
+		})
+		r.printf("\nNote %d: %s\n", n.N, text)
+	}
+	r.printf("\n")
+}
+
+// reflect offers section i's reflection prompt when there is a journal
+// to keep it in. It reports false at the end of input.
+func (r *linear) reflect(i int) bool {
+	j := r.opts.Journal
+	s := sections[i]
+	if j == nil || j.Locked() || s.Prompt == "" {
+		return true
+	}
+	r.printf("Reflection: %s\n", s.Prompt)
+	text, ok := r.askRaw("Type your reflection on one line and press enter, or just press enter to let it pass:")
+	if !ok {
+		return false
+	}
+	if strings.TrimSpace(text) == "" {
+		return true
+	}
+	if _, err := j.Add(journal.Entry{Section: s.Title, Prompt: s.Prompt, Text: text}); err != nil {
+		r.printf("Could not keep it in the journal: %v\n", err)
+	} else {
+		r.printf("Kept in your journal.\n")
+	}
+	return true
+}
+
+// command reads commands after section i until one moves: to the
+// section it returns, which is one past the last once the reading is
+// done. It reports false to quit, or at the end of input.
+func (r *linear) command(i int) (int, bool) {
+	for {
+		raw, ok := r.askRaw(fmt.Sprintf("End of section %d of %d. Command:", i+1, len(sections)))
+		if !ok {
+			return 0, false
+		}
+		cmd := strings.ToLower(strings.TrimSpace(raw))
+		if word, note, _ := strings.Cut(strings.TrimSpace(raw), " "); strings.EqualFold(word, "m") {
+			r.mark(i, strings.TrimSpace(note))
+			continue
+		}
+		switch cmd {
+		case "", "n":
+			return i + 1, true
+		case "b", "p":
+			if i > 0 {
+				return i - 1, true
+			}
+			r.printf("This is the first section.\n")
+		case "r":
+			return i, true
+		case "c":
+			r.contents()
+		case "h", "?":
+			r.printf("%s\n", linearHelp)
+		case "q":
+			return 0, false
+		default:
+			if n, err := strconv.Atoi(cmd); err == nil && n >= 1 && n <= len(sections) {
+				return n - 1, true
+			}
+			r.printf("Unknown command %q. %s\n", cmd, linearHelp)
+		}
+	}
+}
+
+// mark highlights section i, with note if one was given.
+func (r *linear) mark(i int, note string) {
+	if r.opts.Highlights == nil {
+		r.printf("Highlights are not kept in this session.\n")
+		return
+	}
+	s := sections[i]
+	h := highlight.Highlight{Section: s.Title, Number: i + 1, Line: -1, Text: s.KeyLine, Note: note}
+	if err := r.opts.Highlights.Add(h); err != nil {
+		r.printf("Could not keep the highlight: %v\n", err)
+		return
+	}
+	r.printf("Section %d highlighted.\n", i+1)
+}
+
+// contents lists the sections by number.
+func (r *linear) contents() {
+	r.printf("Contents:\n")
+	for i, s := range sections {
+		r.printf("%d. %s\n", i+1, s.Title)
+	}
+}
+
+// save records section i as the place to resume, or clears it once
+// every section has been read.
+func (r *linear) save(i int) {
+	if r.opts.Progress == "" {
+		return
+	}
+	var err error
+	if i >= len(sections) {
+		err = ClearProgress(r.opts.Progress)
+	} else {
+		err = SaveProgress(r.opts.Progress, Progress{Section: sections[i].Title, Line: len(sections[i].Lines), Time: time.Now()})
+	}
+	if err != nil {
+		r.printf("Could not save your place: %v\n", err)
+	}
+}
+
+// leave says goodbye, with the place kept for next time.
+func (r *linear) leave(i int) error {
+	r.printf("\nYour place, section %d, is saved. Om. You are a god in training.\n", i+1)
+	return r.w.Flush()
+}
+
+// scanLines splits input at a newline, a carriage return, or both, so
+// enter works from a terminal in raw mode as well as a cooked one.
+func (r *linear) scanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if r.cr && len(data) > 0 && data[0] == '\n' {
+		r.cr = false
+		return 1, nil, nil // The \n of a \r\n
+	}
+	r.cr = false
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		r.cr = data[i] == '\r'
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,135 @@
+package invocation
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/highlight"
+)
+
+// newNoteInput is the prompt for a highlight's optional note.
+func newNoteInput() textinput.Model {
+	in := textinput.New()
+	in.Prompt = "note: "
+	in.Placeholder = "optional"
+	in.CharLimit = 280
+	return in
+}
+
+// currentLine is the body line the reader is at: the newest revealed
+// while following the reveal, or the first in view once scrolled back.
+// It reports false before the body has begun.
+func (m Model) currentLine() (int, bool) {
+	section := sections[m.sectionIndex]
+	if m.follow {
+		for i := min(m.lineIndex, len(section.Lines)) - 1; i >= 0; i-- {
+			if section.Lines[i] != "" {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	for i := 0; i < m.lineIndex && i < len(section.Lines); i++ {
+		if section.Lines[i] != "" && m.rowOf(i) >= m.viewport.YOffset {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// mark highlights the current line, or the whole section, asking for
+// a note first. Marking what is already highlighted removes it.
+func (m Model) mark(whole bool) (tea.Model, tea.Cmd) {
+	switch m.phase {
+	case phaseTitleReveal, phaseKeyLineTyping, phaseBodyReveal, phaseWaitingForNext:
+	default:
+		return m, nil
+	}
+	section := sections[m.sectionIndex]
+	h := highlight.Highlight{Section: section.Title, Number: m.sectionIndex + 1, Line: -1, Text: section.KeyLine}
+	if !whole {
+		i, ok := m.currentLine()
+		if !ok {
+			return m, nil
+		}
+		h.Line, h.Text = i, strings.TrimSpace(strings.ReplaceAll(StripNoteRefs(section.Lines[i]), "**", ""))
+	}
+
+	if m.highlights.Has(h.Section, h.Line) {
+		if err := m.highlights.Remove(h.Section, h.Line); err != nil {
+			m.status = "highlights: " + err.Error()
+		} else {
+			m.status = "Highlight removed."
+		}
+		return m, nil
+	}
+	m.pending = h
+	m.noting = true
+	m.noteIn.Reset()
+	return m, m.noteIn.Focus()
+}
+
+// updateNote types the highlight's note: enter keeps the highlight,
+// with whatever was written, and esc lets it go.
+func (m Model) updateNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.saveProgress()
+		m.savePace()
+		return m, tea.Quit
+	case "enter":
+		m.noting = false
+		m.noteIn.Blur()
+		h := m.pending
+		h.Note = strings.TrimSpace(m.noteIn.Value())
+		if err := m.highlights.Add(h); err != nil {
+			m.status = "highlights: " + err.Error()
+		} else {
+			m.status = "Highlighted."
+		}
+		return m, nil
+	case "esc":
+		m.noting = false
+		m.noteIn.Blur()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.noteIn, cmd = m.noteIn.Update(msg)
+	return m, cmd
+}
+
+// marked puts the highlight marker in the margin before each row's
+// text. Rows without room for it are left as they are.
+func (m Model) marked(rows []string) []string {
+	c := m.fitCache()
+	if c.mark == "" {
+		c.mark = m.styles.Dim.Render("▍")
+	}
+	out := make([]string, len(rows))
+	for k, row := range rows {
+		n := len(row) - len(strings.TrimLeft(row, " "))
+		if n < 2 || n == len(row) {
+			out[k] = row
+			continue
+		}
+		out[k] = row[:n-2] + c.mark + row[n-1:]
+	}
+	return out
+}
+
+// footer is the note prompt while one is typed, then a word on what
+// was kept, and otherwise the key hint.
+func (m Model) footer(w int) string {
+	switch {
+	case m.noting:
+		m.noteIn.Width = max(10, min(w-34, 32))
+		line := m.noteIn.View() + m.styles.Prompt.Render("  enter keep · esc cancel")
+		return lipgloss.PlaceHorizontal(w, lipgloss.Center, line)
+	case m.status != "":
+		return lipgloss.PlaceHorizontal(w, lipgloss.Center, m.styles.Prompt.Render(truncate(m.status, w-2)))
+	}
+	return m.hint(w)
+}
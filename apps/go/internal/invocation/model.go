@@ -3,11 +3,24 @@ package invocation
 import (
 	"log"
 	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/highlight"
+	"github.com/gorkolas/cybertantra/internal/journal"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
 )
 
 var progressLog *log.Logger
@@ -29,7 +42,9 @@ func init() {
 type Section struct {
 	Title   string
 	KeyLine string
-	Lines   []string // Body lines for progressive reveal
+	Lines   []string // Body lines for progressive reveal; [^ID] cites a note
+	Prompt  string   // Reflection question asked after the section, if any
+	Notes   []Note   // References cited in Lines, defined in the manifesto
 }
 
 // The Invocation content - structured for progressive reveal
@@ -51,7 +66,7 @@ var sections = []Section{
 			"A black hole is forming at the center of this transformation.",
 			"Those who move now will be accelerated outward.",
 			"Those not paying attention will be sucked in",
-			"and trapped by its gravity.",
+			"and trapped by its gravity.[^1]",
 		},
 	},
 	{
@@ -238,20 +253,8 @@ const (
 	phaseBodyReveal
 	phaseWaitingForNext
 	phaseClosing
-)
-
-// Colors - neon CRT palette (brightened)
-var (
-	colorYellow  = lipgloss.Color("#ffef7c")
-	colorCyan    = lipgloss.Color("#5ad4ff")
-	colorMagenta = lipgloss.Color("#ff66cc")
-	colorGreen   = lipgloss.Color("#6dd835")
-	colorWhite   = lipgloss.Color("#ffffff")
-	colorBright  = lipgloss.Color("#f0f0f0")
-	colorText    = lipgloss.Color("#d0d0d0")
-	colorFaded   = lipgloss.Color("#909090")
-	colorMuted   = lipgloss.Color("#707070")
-	colorDim     = lipgloss.Color("#505050")
+	phaseReflection
+	phaseFarewell // The exit screen, fading before the program ends
 )
 
 // Styles
@@ -267,39 +270,63 @@ type Styles struct {
 	Prompt    lipgloss.Style
 }
 
-func NewStyles(r *lipgloss.Renderer) Styles {
+func NewStyles(r *lipgloss.Renderer, th theme.Theme) Styles {
 	if r == nil {
 		r = lipgloss.DefaultRenderer()
 	}
+	// Below 256 colours the greys merge with body text; faint keeps them apart
+	quiet := theme.Degraded(r)
 	return Styles{
 		Title: r.NewStyle().
-			Foreground(colorCyan).
+			Foreground(th.Title).
 			Bold(true),
 		KeyLine: r.NewStyle().
-			Foreground(colorYellow).
+			Foreground(th.Accent).
 			Bold(true),
 		BodyNew: r.NewStyle().
-			Foreground(colorWhite),
+			Foreground(th.Flash),
 		Body: r.NewStyle().
-			Foreground(colorText),
+			Foreground(th.Text),
 		BodyFaded: r.NewStyle().
-			Foreground(colorFaded),
+			Foreground(th.Faded).
+			Faint(quiet),
 		Bold: r.NewStyle().
-			Foreground(colorYellow).
+			Foreground(th.Accent).
 			Bold(true),
 		BoldNew: r.NewStyle().
-			Foreground(colorWhite).
+			Foreground(th.Flash).
 			Bold(true),
 		Dim: r.NewStyle().
-			Foreground(colorMuted),
+			Foreground(th.Muted).
+			Faint(quiet),
 		Prompt: r.NewStyle().
-			Foreground(colorFaded),
+			Foreground(th.Faded).
+			Faint(quiet),
 	}
 }
 
+// Options carries per-session dependencies into the invocation.
+type Options struct {
+	Journal  *journal.Journal // Receives section reflections; nil disables prompts
+	Theme    theme.Theme
+	Keys     keys.Map // The defaults when unset
+	Progress string   // Where the practitioner's place is saved; "" saves nothing
+	Pace     string   // Where the learned reading speed is kept; "" learns for this session only
+
+	Highlights *highlight.Store // Lines marked to come back to; nil disables marking
+	Links      bool             // The terminal opens OSC 8 hyperlinks
+	Echo       bool             // The accessible reader's input is a terminal in raw mode, so it echoes and edits lines itself
+}
+
 // Model
 type Model struct {
 	styles        Styles
+	keys          keys.Map
+	help          help.Model
+	theme         theme.Theme
+	renderer      *lipgloss.Renderer
+	degraded      bool // 16 colours or fewer: fade with attributes
+	monochrome    bool // No colour at all
 	phase         phase
 	sectionIndex  int
 	charIndex     int   // Typewriter position
@@ -308,116 +335,341 @@ type Model struct {
 	width         int
 	height        int
 	ready         bool
-	scrollOffset  int // Scroll position for long content
+	lines         []string       // contentLines as last laid out
+	viewport      viewport.Model // Window onto content taller than the screen
+	follow        bool           // Keep the newest revealed line in view
+	scrollTarget  int            // Where smooth scrolling is heading
+	scrolling     bool           // A scroll tick is in flight
+	reveal        uint64         // Generation of the type and line ticks; see chains
+	fade          uint64         // Of the fade ticks
+	scroll        uint64         // Of the scroll ticks
+	jumping       bool           // Scroll to jumpLine at the next layout
+	jumpLine      int
+	journal       *journal.Journal
+	editor        textarea.Model
+	reflected     map[int]bool // Sections already reflected on (or skipped)
+	cache         *renderCache // Shared by every copy; see renderCache
+	progressPath  string
+	confirming    bool // The quit dialog is open
+	footnotes     bool // The notes popup is open
+	links         bool // Notes' links are clickable
+	hud           bool // The status bar is shown
+	pace          Pace // Learned reading speed
+	pacePath      string
+	paceUnsaved   bool      // Learned from since it was last saved
+	revealStart   time.Time // When this section began to reveal, to time the reading
+	lineShown     time.Time // When the newest body line appeared
+	farewellFrame int       // Position in farewellFade
+	highlights    *highlight.Store
+	noting        bool                // The highlight's note is being typed
+	noteIn        textinput.Model     // Its prompt
+	pending       highlight.Highlight // What the note is for
+	status        string              // A word on what was just kept, until the next key
 }
 
-// Messages
-type typeTickMsg struct{}
-type lineTickMsg struct{}
-type fadeTickMsg struct{}
+// Messages. Each tick carries the generation of the chain that
+// scheduled it.
+type typeTickMsg struct{ gen uint64 }
+type lineTickMsg struct{ gen uint64 }
+type fadeTickMsg struct{ gen uint64 }
 
-func typeTick() tea.Cmd {
-	return tea.Tick(20*time.Millisecond, func(t time.Time) tea.Msg {
-		return typeTickMsg{}
+// chains hands out tick generations, unique across every reader. A
+// chain of ticks is scheduled under one, and a reader drops ticks that
+// carry any but its current one, so a chain that was restarted, or one
+// left behind by a reader that was replaced, can't run alongside.
+var chains atomic.Uint64
+
+// The reveal's pace, which the status bar also estimates time from.
+// Body lines wait as long as the practitioner's Pace says.
+const (
+	typeDelay      = 20 * time.Millisecond  // Per key line character
+	keyLinePause   = 300 * time.Millisecond // After the key line, before the body
+	paragraphPause = 400 * time.Millisecond // After a blank line, at the default pace
+	fadeFrame      = 40 * time.Millisecond
+)
+
+func (m Model) typeTick() tea.Cmd {
+	gen := m.reveal
+	return tea.Tick(typeDelay, func(t time.Time) tea.Msg {
+		return typeTickMsg{gen}
 	})
 }
 
-func lineTick() tea.Cmd {
-	return tea.Tick(750*time.Millisecond, func(t time.Time) tea.Msg {
-		return lineTickMsg{}
+// lineTick waits for the newest body line to be read, at the
+// practitioner's pace, before revealing the next.
+func (m Model) lineTick() tea.Cmd {
+	d := keyLinePause
+	if i := m.lineIndex - 1; i >= 0 && i < len(sections[m.sectionIndex].Lines) {
+		d = m.pace.lineDelay(m.sectionIndex, i)
+	}
+	gen := m.reveal
+	return tea.Tick(d, func(t time.Time) tea.Msg {
+		return lineTickMsg{gen}
 	})
 }
 
-func fadeTick() tea.Cmd {
-	return tea.Tick(40*time.Millisecond, func(t time.Time) tea.Msg {
-		return fadeTickMsg{}
+func (m Model) fadeTick() tea.Cmd {
+	gen := m.fade
+	return tea.Tick(fadeFrame, func(t time.Time) tea.Msg {
+		return fadeTickMsg{gen}
 	})
 }
 
-func New(r *lipgloss.Renderer) Model {
+func New(r *lipgloss.Renderer, opts Options) Model {
+	if opts.Theme.Name == "" {
+		opts.Theme = theme.Default()
+	}
 	return Model{
-		styles: NewStyles(r),
-		phase:  phaseOpening,
+		styles:       NewStyles(r, opts.Theme),
+		keys:         opts.Keys.Or(),
+		help:         keys.NewHelp(r, opts.Theme),
+		theme:        opts.Theme,
+		renderer:     r,
+		degraded:     theme.Degraded(r),
+		monochrome:   opts.Theme.NoColor,
+		phase:        phaseOpening,
+		follow:       true,
+		journal:      opts.Journal,
+		editor:       journal.NewEditor(),
+		reflected:    make(map[int]bool),
+		cache:        newRenderCache(),
+		progressPath: opts.Progress,
+		hud:          true,
+		pace:         LoadPace(opts.Pace),
+		pacePath:     opts.Pace,
+		highlights:   opts.Highlights,
+		noteIn:       newNoteInput(),
+		links:        opts.Links,
+		reveal:       chains.Add(1),
+		fade:         chains.Add(1),
+		scroll:       chains.Add(1),
 	}
 }
 
 // NewAtSection creates an invocation model starting at a specific section
-func NewAtSection(r *lipgloss.Renderer, sectionIndex int) Model {
+func NewAtSection(r *lipgloss.Renderer, sectionIndex int, opts Options) Model {
 	if sectionIndex < 0 || sectionIndex >= len(sections) {
 		sectionIndex = 0
 	}
-	return Model{
-		styles:       NewStyles(r),
-		phase:        phaseTitleReveal,
-		sectionIndex: sectionIndex,
-	}
+	m := New(r, opts)
+	m.phase = phaseTitleReveal
+	m.sectionIndex = sectionIndex
+	return m
+}
+
+// Capturing reports whether keystrokes are going into the reflection
+// editor or a highlight's note, or closing the notes, so the parent
+// must not treat them as navigation.
+func (m Model) Capturing() bool {
+	return m.phase == phaseReflection || m.phase == phaseFarewell || m.confirming || m.noting || m.footnotes
 }
 
 func (m Model) Init() tea.Cmd {
 	// If starting at a specific section (not opening), begin typewriter
 	if m.phase == phaseTitleReveal {
-		return typeTick()
+		return m.typeTick()
 	}
 	return nil
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+// Resume starts the ticks again after the reader was away, where they
+// stopped: the reveal, the newest lines' fade, a scroll short of its
+// target. Ticks scheduled before it left are dropped.
+func (m Model) Resume() (tea.Model, tea.Cmd) {
+	m.reveal, m.fade, m.scroll = chains.Add(1), chains.Add(1), chains.Add(1)
+	var cmds []tea.Cmd
+	switch m.phase {
+	case phaseTitleReveal:
+		cmds = append(cmds, m.typeTick())
+	case phaseKeyLineTyping, phaseBodyReveal:
+		cmds = append(cmds, m.lineTick())
+	}
+	if slices.ContainsFunc(m.lineOpacity[:min(m.lineIndex, len(m.lineOpacity))], func(o int) bool { return o < 3 }) {
+		cmds = append(cmds, m.fadeTick())
+	}
+	m.scrolling = m.viewport.YOffset != m.scrollTarget
+	if m.scrolling {
+		cmds = append(cmds, m.scrollTick())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		m.status = ""
+		switch {
+		case m.phase == phaseFarewell:
+			// Any key skips the rest of the exit screen
 			return m, tea.Quit
-		case " ":
-			m.scrollOffset = 0 // Reset scroll on advance
+		case m.confirming:
+			return m.updateConfirm(msg)
+		case m.footnotes:
+			return m.updateFootnotes(msg)
+		case m.noting:
+			return m.updateNote(msg)
+		case m.phase == phaseReflection:
+			return m.updateReflection(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m.quit(msg)
+		case key.Matches(msg, m.keys.Advance):
 			return m.advance()
-		case "enter":
-			m.scrollOffset = 0 // Reset scroll on back
+		case key.Matches(msg, m.keys.Back):
 			return m.goBack()
-		case "up", "k":
-			if m.scrollOffset > 0 {
-				m.scrollOffset--
-			}
-			return m, nil
-		case "down", "j":
-			m.scrollOffset++
+		case key.Matches(msg, m.keys.ScrollUp):
+			return m.scrollBy(-1), nil
+		case key.Matches(msg, m.keys.ScrollDown):
+			return m.scrollBy(1), nil
+		case key.Matches(msg, m.keys.PageUp):
+			return m.scrollBy(-m.pageStep()), nil
+		case key.Matches(msg, m.keys.PageDown):
+			return m.scrollBy(m.pageStep()), nil
+		case key.Matches(msg, m.keys.HUD):
+			m.hud = !m.hud
 			return m, nil
-		case "pgup":
-			m.scrollOffset -= 10
-			if m.scrollOffset < 0 {
-				m.scrollOffset = 0
-			}
+		case m.highlights != nil && key.Matches(msg, m.keys.Mark):
+			return m.mark(false)
+		case m.highlights != nil && key.Matches(msg, m.keys.MarkSection):
+			return m.mark(true)
+		case key.Matches(msg, m.keys.Notes):
+			return m.openFootnotes()
+		}
+
+	case tea.MouseMsg:
+		if m.Capturing() || msg.Action != tea.MouseActionPress {
 			return m, nil
-		case "pgdown":
-			m.scrollOffset += 10
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			return m.scrollBy(-wheelStep), nil
+		case tea.MouseButtonWheelDown:
+			return m.scrollBy(wheelStep), nil
+		case tea.MouseButtonLeft:
+			// A click or tap anywhere turns the page, like space
+			return m.advance()
+		}
+		return m, nil
+
+	case scrollTickMsg:
+		if msg.gen != m.scroll {
 			return m, nil
 		}
+		return m.handleScrollTick()
+
+	case farewellTickMsg:
+		return m.handleFarewellTick()
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
+		m.editor.SetWidth(m.editorWidth())
+		return m, nil
+
+	case theme.ChangedMsg:
+		m.theme = msg.Theme
+		m.monochrome = m.theme.NoColor
+		m.styles = NewStyles(m.renderer, m.theme)
+		m.help = keys.NewHelp(m.renderer, m.theme)
+		m.cache.reset()
 		return m, nil
 
 	case typeTickMsg:
+		if msg.gen != m.reveal {
+			return m, nil
+		}
 		return m.handleTypeTick()
 
 	case lineTickMsg:
+		if msg.gen != m.reveal {
+			return m, nil
+		}
 		return m.handleLineTick()
 
 	case fadeTickMsg:
+		if msg.gen != m.fade {
+			return m, nil
+		}
 		return m.handleFadeTick()
+
+	default:
+		// Cursor blink and other editor housekeeping
+		if m.noting {
+			var cmd tea.Cmd
+			m.noteIn, cmd = m.noteIn.Update(msg)
+			return m, cmd
+		}
+		if m.phase == phaseReflection {
+			var cmd tea.Cmd
+			m.editor, cmd = m.editor.Update(msg)
+			return m, cmd
+		}
 	}
 
 	return m, nil
 }
 
+// updateReflection routes keys to the editor: ctrl+s keeps the entry,
+// esc lets the moment pass. Either way the invocation moves on, unless
+// the entry could not be kept: then it stays in the editor to try again.
+func (m Model) updateReflection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "ctrl+s":
+		section := sections[m.sectionIndex]
+		if strings.TrimSpace(m.editor.Value()) != "" {
+			if _, err := m.journal.Add(journal.Entry{
+				Section: section.Title,
+				Prompt:  section.Prompt,
+				Text:    m.editor.Value(),
+			}); err != nil {
+				progressLog.Printf("JOURNAL error=%v", err)
+				m.status = "Not kept, ctrl+s to try again: " + err.Error()
+				return m, nil
+			}
+			progressLog.Printf("REFLECT section=%q", section.Title)
+		}
+		m.editor.Blur()
+		return m.nextSection()
+	case "esc":
+		m.editor.Blur()
+		return m.nextSection()
+	}
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+func (m Model) editorWidth() int {
+	return min(m.textWidth(), 64)
+}
+
+// textWidth is the measure body text wraps to, narrower margins on a
+// narrow terminal.
+func (m Model) textWidth() int {
+	return layout.TextWidth(m.pageWidth())
+}
+
+// gap separates title, key line and body: a blank line, or none when
+// the terminal is short.
+func (m Model) gap() string {
+	if layout.Short(m.height) {
+		return "\n"
+	}
+	return "\n\n"
+}
+
 func (m Model) advance() (tea.Model, tea.Cmd) {
 	switch m.phase {
 	case phaseOpening:
 		m.phase = phaseTitleReveal
 		m.charIndex = 0
 		progressLog.Printf("START section=%q", sections[m.sectionIndex].Title)
-		return m, typeTick()
+		m.reveal = chains.Add(1)
+		return m, m.typeTick()
 
 	case phaseTitleReveal:
 		// Skip typewriter, start body reveal with auto-animation
@@ -426,7 +678,8 @@ func (m Model) advance() (tea.Model, tea.Cmd) {
 		m.phase = phaseBodyReveal
 		m.lineIndex = 0
 		m.lineOpacity = make([]int, len(section.Lines))
-		return m, lineTick()
+		m.reveal = chains.Add(1)
+		return m, m.lineTick()
 
 	case phaseKeyLineTyping:
 		// Skip to body reveal with auto-animation
@@ -434,19 +687,27 @@ func (m Model) advance() (tea.Model, tea.Cmd) {
 		m.phase = phaseBodyReveal
 		m.lineIndex = 0
 		m.lineOpacity = make([]int, len(section.Lines))
-		return m, lineTick()
+		m.reveal = chains.Add(1)
+		return m, m.lineTick()
 
 	case phaseBodyReveal:
-		// Space advances one line instantly (continues auto-animation)
+		// Space advances one line instantly (continues auto-animation).
+		// How soon it came says how fast the newest line was read.
 		section := sections[m.sectionIndex]
+		if m.lineIndex > 0 && !m.lineShown.IsZero() {
+			m.learnPace(words(section.Lines[m.lineIndex-1]), time.Since(m.lineShown))
+		}
 		if m.lineIndex < len(section.Lines) {
 			m.lineIndex++
+			m.lineShown = time.Now()
 			if m.lineIndex <= len(m.lineOpacity) {
 				m.lineOpacity[m.lineIndex-1] = 3
 			}
-			// Continue auto-animation for remaining lines
+			// Continue auto-animation for remaining lines, in place
+			// of the tick that was waiting
 			if m.lineIndex < len(section.Lines) {
-				return m, lineTick()
+				m.reveal = chains.Add(1)
+				return m, m.lineTick()
 			}
 		}
 		// All lines shown
@@ -454,25 +715,58 @@ func (m Model) advance() (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case phaseWaitingForNext:
-		m.sectionIndex++
-		if m.sectionIndex >= len(sections) {
-			m.phase = phaseClosing
-			progressLog.Printf("COMPLETE")
-			return m, nil
+		// Staying with the whole section says how fast it was read
+		if !m.revealStart.IsZero() {
+			m.learnPace(sectionWords(m.sectionIndex), time.Since(m.revealStart))
+			m.revealStart = time.Time{}
 		}
-		m.phase = phaseTitleReveal
-		m.charIndex = 0
-		m.lineIndex = 0
-		progressLog.Printf("NEXT section=%q (%d/%d)", sections[m.sectionIndex].Title, m.sectionIndex+1, len(sections))
-		return m, typeTick()
+		if m.journal != nil && !m.journal.Locked() && sections[m.sectionIndex].Prompt != "" && !m.reflected[m.sectionIndex] {
+			m.reflected[m.sectionIndex] = true
+			m.phase = phaseReflection
+			m.editor.Reset()
+			return m, m.editor.Focus()
+		}
+		return m.nextSection()
 
 	case phaseClosing:
-		return m, tea.Quit
+		return m.farewell()
 	}
 
 	return m, nil
 }
 
+func (m Model) nextSection() (tea.Model, tea.Cmd) {
+	m.sectionIndex++
+	if m.sectionIndex >= len(sections) {
+		m.phase = phaseClosing
+		m.saveProgress()
+		m.savePace()
+		progressLog.Printf("COMPLETE")
+		return m, nil
+	}
+	m.phase = phaseTitleReveal
+	m.charIndex = 0
+	m.lineIndex = 0
+	m.saveProgress()
+	m.savePace()
+	progressLog.Printf("NEXT section=%q (%d/%d)", sections[m.sectionIndex].Title, m.sectionIndex+1, len(sections))
+	m.reveal = chains.Add(1)
+	return m, m.typeTick()
+}
+
+// revealAll shows the current section whole, waiting for the next.
+func (m Model) revealAll() Model {
+	section := sections[m.sectionIndex]
+	m.phase = phaseWaitingForNext
+	m.charIndex = len(section.KeyLine)
+	m.lineIndex = len(section.Lines)
+	m.lineOpacity = make([]int, len(section.Lines))
+	for i := range m.lineOpacity {
+		m.lineOpacity[i] = 3
+	}
+	return m
+}
+
 func (m Model) goBack() (tea.Model, tea.Cmd) {
 	// From closing, go back to last section
 	if m.phase == phaseClosing {
@@ -524,14 +818,18 @@ func (m Model) goBack() (tea.Model, tea.Cmd) {
 func (m Model) handleTypeTick() (tea.Model, tea.Cmd) {
 	if m.phase == phaseTitleReveal {
 		section := sections[m.sectionIndex]
+		if m.charIndex == 0 {
+			m.revealStart = time.Now()
+		}
 		if m.charIndex < len(section.KeyLine) {
 			m.charIndex++
-			return m, typeTick()
+			return m, m.typeTick()
 		}
 		// Typewriter complete, start body reveal
 		m.phase = phaseKeyLineTyping
-		return m, tea.Tick(300*time.Millisecond, func(t time.Time) tea.Msg {
-			return lineTickMsg{}
+		gen := m.reveal
+		return m, tea.Tick(keyLinePause, func(t time.Time) tea.Msg {
+			return lineTickMsg{gen}
 		})
 	}
 	return m, nil
@@ -549,13 +847,8 @@ func (m Model) handleLineTick() (tea.Model, tea.Cmd) {
 	if m.phase == phaseBodyReveal {
 		if m.lineIndex < len(section.Lines) {
 			m.lineIndex++
-			// Longer pause for empty lines (paragraph breaks)
-			if section.Lines[m.lineIndex-1] == "" {
-				return m, tea.Batch(fadeTick(), tea.Tick(400*time.Millisecond, func(t time.Time) tea.Msg {
-					return lineTickMsg{}
-				}))
-			}
-			return m, tea.Batch(fadeTick(), lineTick())
+			m.lineShown = time.Now()
+			return m, tea.Batch(m.fadeTick(), m.lineTick())
 		}
 		// All lines revealed, keep fading until all at full opacity
 		allFull := true
@@ -569,7 +862,7 @@ func (m Model) handleLineTick() (tea.Model, tea.Cmd) {
 			m.phase = phaseWaitingForNext
 			return m, nil
 		}
-		return m, fadeTick()
+		return m, m.fadeTick()
 	}
 
 	return m, nil
@@ -589,7 +882,7 @@ func (m Model) handleFadeTick() (tea.Model, tea.Cmd) {
 
 	// Continue fading if any line not at full opacity
 	if changed && m.phase == phaseBodyReveal {
-		return m, fadeTick()
+		return m, m.fadeTick()
 	}
 
 	// All lines at full opacity - transition to waiting
@@ -600,31 +893,41 @@ func (m Model) handleFadeTick() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// wrapText wraps text to fit within maxWidth characters
-func wrapText(text string, maxWidth int) string {
-	if maxWidth <= 0 || len(text) <= maxWidth {
-		return text
-	}
-
-	var result strings.Builder
-	words := strings.Fields(text)
-	lineLen := 0
-
-	for i, word := range words {
-		wordLen := len(word)
-		if lineLen+wordLen+1 > maxWidth && lineLen > 0 {
-			result.WriteString("\n")
-			lineLen = 0
+// fadeStyles picks body and bold styles for an opacity level. With
+// enough colours the fade is a ramp of greys; below that the greys
+// collapse, so the reveal steps from faint to normal instead, and with
+// no colour at all bold text is also underlined to stand apart.
+func (m Model) fadeStyles(opacity int) (body, bold lipgloss.Style) {
+	if m.degraded {
+		body, bold = m.styles.Body, m.styles.Bold
+		if opacity < 2 {
+			body = body.Faint(true)
+			bold = bold.Faint(true)
 		}
-		if lineLen > 0 {
-			result.WriteString(" ")
-			lineLen++
+		if m.monochrome {
+			bold = bold.Underline(true)
 		}
-		result.WriteString(word)
-		lineLen += wordLen
-		_ = i
+		return body, bold
 	}
-	return result.String()
+
+	// Select colors based on opacity level
+	var textColor, boldColor lipgloss.Color
+	th := m.theme
+	switch opacity {
+	case 0:
+		textColor = th.Dim
+		boldColor = th.Muted
+	case 1:
+		textColor = th.Faded
+		boldColor = th.Faded
+	case 2:
+		textColor = th.Text
+		boldColor = th.Accent
+	default: // 3 = full
+		textColor = th.Bright
+		boldColor = th.Accent
+	}
+	return m.styles.Body.Foreground(textColor), m.styles.Bold.Foreground(boldColor)
 }
 
 // renderLine handles bold markdown syntax with fade-in effect
@@ -634,55 +937,63 @@ func (m Model) renderLine(line string, opacity int) string {
 		return ""
 	}
 
-	// Wrap text to fit terminal width
-	maxWidth := m.width - 12 // Account for padding
-	if maxWidth < 30 {
-		maxWidth = 30
+	line = ReplaceNoteRefs(line, func(id string) string {
+		if n := EndnoteNumber(endnotes(), m.sectionIndex, id); n > 0 {
+			return Superscript(n)
+		}
+		return ""
+	})
+
+	body, bold := m.fadeStyles(opacity)
+	return strings.Join(typeset(line, m.textWidth(), body, bold), "\n")
+}
+
+// head is the section title and key line, with the typewriter cursor
+// while the key line is typed, ending where the body starts. Once typed
+// it comes from the render cache.
+func (m Model) head() []string {
+	section := sections[m.sectionIndex]
+	typing := m.phase == phaseTitleReveal && m.charIndex < len(section.KeyLine)
+	c := m.fitCache()
+	if lines, ok := c.heads[m.sectionIndex]; ok && !typing {
+		return slices.Clip(lines)
 	}
-	line = wrapText(line, maxWidth)
 
-	// Select colors based on opacity level
-	var textColor, boldColor lipgloss.Color
-	switch opacity {
-	case 0:
-		textColor = colorDim
-		boldColor = colorMuted
-	case 1:
-		textColor = colorFaded
-		boldColor = colorFaded
-	case 2:
-		textColor = colorText
-		boldColor = colorYellow
-	default: // 3 = full
-		textColor = colorBright
-		boldColor = colorYellow
-	}
-
-	bodyStyle := m.styles.Body.Foreground(textColor)
-	boldStyle := m.styles.Bold.Foreground(boldColor)
-
-	// Check for **bold** pattern
-	if strings.Contains(line, "**") {
-		parts := strings.Split(line, "**")
-		var result strings.Builder
-		for i, part := range parts {
-			if i%2 == 1 {
-				result.WriteString(boldStyle.Render(part))
-			} else {
-				result.WriteString(bodyStyle.Render(part))
-			}
-		}
-		return result.String()
+	var b strings.Builder
+	s := m.styles
+	m.writeWrapped(&b, s.Title, section.Title)
+	b.WriteString(m.gap())
+	if typing {
+		m.writeWrapped(&b, s.KeyLine, section.KeyLine[:m.charIndex])
+		b.WriteString(s.Dim.Render("▌"))
+	} else {
+		m.writeWrapped(&b, s.KeyLine, section.KeyLine)
 	}
+	b.WriteString(m.gap())
 
-	return bodyStyle.Render(line)
+	lines := m.centre(b.String())
+	lines = lines[:len(lines)-1] // The body starts on the gap's last line
+	if !typing {
+		c.heads[m.sectionIndex] = lines
+	}
+	return slices.Clip(lines)
 }
 
-func (m Model) View() string {
-	if !m.ready {
-		return ""
+// writeWrapped writes text wrapped to the page's measure, each line
+// in style, leaving the last line open.
+func (m Model) writeWrapped(b *strings.Builder, style lipgloss.Style, text string) {
+	for i, line := range layout.Wrap(text, m.textWidth()) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(style.Render(line))
 	}
+}
 
+// contentLines renders what the current phase shows, each line
+// centred and padded to the page width, before any scrolling. Body
+// lines come from the render cache.
+func (m Model) contentLines() []string {
 	var b strings.Builder
 	s := m.styles
 
@@ -692,41 +1003,49 @@ func (m Model) View() string {
 	case phaseOpening:
 		b.WriteString(titleStyle.Render("॥ CYBERTANTRA ॥"))
 		b.WriteString("\n\n")
-		b.WriteString(s.Dim.Render("Part I: The Invocation"))
+		b.WriteString(s.Dim.Render(Title))
 
 	case phaseTitleReveal, phaseKeyLineTyping, phaseBodyReveal, phaseWaitingForNext:
 		section := sections[m.sectionIndex]
-
-		// Section title
-		b.WriteString(s.Title.Render(section.Title))
-		b.WriteString("\n\n")
-
-		// Key line with typewriter
-		if m.phase == phaseTitleReveal && m.charIndex < len(section.KeyLine) {
-			displayed := section.KeyLine[:m.charIndex]
-			b.WriteString(s.KeyLine.Render(displayed))
-			b.WriteString(s.Dim.Render("▌"))
-		} else {
-			b.WriteString(s.KeyLine.Render(section.KeyLine))
+		lines := m.head()
+		if m.highlights.Has(section.Title, -1) {
+			lines = m.marked(lines)
 		}
-		b.WriteString("\n\n")
 
 		// Body lines - progressive reveal with fade effect
 		if m.phase >= phaseBodyReveal || m.phase == phaseKeyLineTyping {
 			for i := 0; i < m.lineIndex && i < len(section.Lines); i++ {
-				line := section.Lines[i]
 				opacity := 0
 				if i < len(m.lineOpacity) {
 					opacity = m.lineOpacity[i]
 				}
-				if line == "" {
-					b.WriteString("\n")
-				} else {
-					b.WriteString(m.renderLine(line, opacity))
-					b.WriteString("\n")
+				switch {
+				case section.Lines[i] == "":
+					lines = append(lines, m.cache.blank)
+				case m.highlights.Has(section.Title, i):
+					lines = append(lines, m.marked(m.bodyLine(i, opacity))...)
+				default:
+					lines = append(lines, m.bodyLine(i, opacity)...)
 				}
 			}
 		}
+		return append(lines, m.cache.blank)
+
+	case phaseReflection:
+		section := sections[m.sectionIndex]
+		m.writeWrapped(&b, s.Title, section.Title)
+		b.WriteString(m.gap())
+		for _, line := range layout.Wrap(section.Prompt, m.editorWidth()) {
+			b.WriteString(s.KeyLine.Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(m.editor.View())
+		b.WriteString("\n\n")
+		b.WriteString(s.Prompt.Render("ctrl+s keep in journal · esc let it pass"))
+
+	case phaseFarewell:
+		b.WriteString(m.farewellLines())
 
 	case phaseClosing:
 		b.WriteString(titleStyle.Render("॥ ॐ ॥"))
@@ -736,129 +1055,21 @@ func (m Model) View() string {
 		b.WriteString(s.Prompt.Render("You are a god in training."))
 	}
 
-	// Build content lines array
-	content := b.String()
-	contentParts := strings.Split(content, "\n")
-
-	w := m.width
-	if w < 40 {
-		w = 40
-	}
-	blankLine := strings.Repeat(" ", w)
-
-	// Center each content line horizontally and fill to full width
-	var lines []string
-	for _, line := range contentParts {
-		if line == "" {
-			lines = append(lines, blankLine)
-			continue
-		}
-		lineLen := lipgloss.Width(line)
-		leftPad := (w - lineLen) / 2
-		if leftPad < 0 {
-			leftPad = 0
-		}
-		rightPad := w - leftPad - lineLen
-		if rightPad < 0 {
-			rightPad = 0
-		}
-		centeredLine := strings.Repeat(" ", leftPad) + line + strings.Repeat(" ", rightPad)
-		lines = append(lines, centeredLine)
-	}
-
-	contentHeight := len(lines)
-	viewportHeight := m.height - 2 // Reserve space for scroll indicator
-
-	// If content fits, center it vertically (no scrolling needed)
-	if contentHeight <= viewportHeight {
-		topPad := (viewportHeight - contentHeight) / 2
-		if topPad < 0 {
-			topPad = 0
-		}
-
-		var result strings.Builder
-		lineNum := 0
-
-		// Top padding
-		for i := 0; i < topPad && lineNum < m.height; i++ {
-			result.WriteString(blankLine)
-			result.WriteString("\n")
-			lineNum++
-		}
-
-		// Content
-		for _, line := range lines {
-			if lineNum >= m.height {
-				break
-			}
-			result.WriteString(line)
-			result.WriteString("\n")
-			lineNum++
-		}
-
-		// Bottom padding
-		for lineNum < m.height {
-			result.WriteString(blankLine)
-			result.WriteString("\n")
-			lineNum++
-		}
-
-		return result.String()
-	}
-
-	// Content overflows - use scroll offset
-	maxScroll := contentHeight - viewportHeight
-	if maxScroll < 0 {
-		maxScroll = 0
-	}
-
-	// Clamp scroll offset
-	scrollOffset := m.scrollOffset
-	if scrollOffset > maxScroll {
-		scrollOffset = maxScroll
-	}
-	if scrollOffset < 0 {
-		scrollOffset = 0
-	}
-
-	var result strings.Builder
-	lineNum := 0
-
-	// Render visible portion of content
-	for i := scrollOffset; i < len(lines) && lineNum < viewportHeight; i++ {
-		result.WriteString(lines[i])
-		result.WriteString("\n")
-		lineNum++
-	}
+	return m.centre(b.String())
+}
 
-	// Pad to fill viewport if needed
-	for lineNum < viewportHeight {
-		result.WriteString(blankLine)
-		result.WriteString("\n")
-		lineNum++
+// hint is the footer naming the keys that matter right now, centred
+// in w. The reflection editor shows its own.
+func (m Model) hint(w int) string {
+	if m.phase == phaseReflection || m.phase == phaseFarewell {
+		return strings.Repeat(" ", w)
 	}
-
-	// Scroll indicator
-	var scrollInfo string
-	if scrollOffset == 0 {
-		scrollInfo = s.Dim.Render("↓ scroll down")
-	} else if scrollOffset >= maxScroll {
-		scrollInfo = s.Dim.Render("↑ scroll up")
-	} else {
-		scrollInfo = s.Dim.Render("↑↓ scroll")
+	c := m.fitCache()
+	if c.hint == "" {
+		h := m.help
+		h.Width = w
+		line := h.ShortHelpView(m.keys.ReaderView().ShortHelp())
+		c.hint = lipgloss.PlaceHorizontal(w, lipgloss.Center, line)
 	}
-
-	indicatorLen := lipgloss.Width(scrollInfo)
-	indicatorPad := (w - indicatorLen) / 2
-	if indicatorPad < 0 {
-		indicatorPad = 0
-	}
-	result.WriteString(strings.Repeat(" ", indicatorPad))
-	result.WriteString(scrollInfo)
-	result.WriteString("\n")
-
-	// Fill remaining height
-	result.WriteString(blankLine)
-
-	return result.String()
+	return c.hint
 }
@@ -0,0 +1,44 @@
+package invocation
+
+import (
+	"io"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestStaleTicksDropped checks that a tick scheduled before the reveal
+// was restarted, or by another reader, moves nothing.
+func TestStaleTicksDropped(t *testing.T) {
+	r := lipgloss.NewRenderer(io.Discard)
+	m := NewAtSection(r, 1, Options{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = next.(Model)
+	next, _ = m.advance()
+	m = next.(Model)
+	if m.phase != phaseBodyReveal {
+		t.Fatalf("phase = %v after skipping the title, want the body reveal", m.phase)
+	}
+	stale := lineTickMsg{m.reveal}
+
+	// Space reveals a line and schedules the next in place of the tick
+	// that was waiting
+	next, _ = m.advance()
+	m = next.(Model)
+	shown := m.lineIndex
+	next, _ = m.Update(stale)
+	if got := next.(Model).lineIndex; got != shown {
+		t.Errorf("a stale line tick revealed line %d, want it dropped at %d", got, shown)
+	}
+	next, _ = m.Update(lineTickMsg{m.reveal})
+	if got := next.(Model).lineIndex; got != shown+1 {
+		t.Errorf("the current line tick left the reveal at %d, want %d", got, shown+1)
+	}
+
+	// A reader opened in its place ignores the old one's ticks
+	fresh := NewAtSection(r, 1, Options{})
+	if _, cmd := fresh.Update(typeTickMsg{m.reveal}); cmd != nil {
+		t.Error("a new reader took another reader's type tick")
+	}
+}
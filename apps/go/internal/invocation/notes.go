@@ -0,0 +1,219 @@
+package invocation
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gorkolas/cybertantra/assets"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Note is a reference a section cites inline as [^ID], markdown style.
+type Note struct {
+	ID   string
+	Text string // Markdown; links as [label](url)
+}
+
+var (
+	noteRefPattern = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+	noteDefPattern = regexp.MustCompile(`(?m)^\[\^([^\]\s]+)\]:[ \t]*(.*\S)[ \t]*$`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// The notes the sections cite are defined once, at the end of the
+// manifesto, as the document defines them.
+func init() {
+	citeNotes(sections, ParseNotes(assets.Manifesto))
+}
+
+// ParseNotes reads the footnote definitions in markdown, one to a line
+// as [^ID]: text, in the order they are written.
+func ParseNotes(md string) []Note {
+	var notes []Note
+	for _, m := range noteDefPattern.FindAllStringSubmatch(md, -1) {
+		notes = append(notes, Note{ID: m[1], Text: m[2]})
+	}
+	return notes
+}
+
+// citeNotes gives each section the notes from defs its lines cite, in
+// the order they are first cited.
+func citeNotes(sections []Section, defs []Note) {
+	for i := range sections {
+		s := &sections[i]
+		for _, line := range s.Lines {
+			for _, m := range noteRefPattern.FindAllStringSubmatch(line, -1) {
+				if slices.ContainsFunc(s.Notes, func(n Note) bool { return n.ID == m[1] }) {
+					continue
+				}
+				if j := slices.IndexFunc(defs, func(n Note) bool { return n.ID == m[1] }); j >= 0 {
+					s.Notes = append(s.Notes, defs[j])
+				}
+			}
+		}
+	}
+}
+
+// StripNoteRefs removes inline note markers, for views without notes.
+func StripNoteRefs(line string) string {
+	return noteRefPattern.ReplaceAllString(line, "")
+}
+
+// ReplaceNoteRefs replaces each inline note marker with f(id).
+func ReplaceNoteRefs(line string, f func(id string) string) string {
+	return noteRefPattern.ReplaceAllStringFunc(line, func(ref string) string {
+		return f(noteRefPattern.FindStringSubmatch(ref)[1])
+	})
+}
+
+// ReplaceLinks replaces each markdown link with f(label, url).
+func ReplaceLinks(text string, f func(label, url string) string) string {
+	return linkPattern.ReplaceAllStringFunc(text, func(link string) string {
+		m := linkPattern.FindStringSubmatch(link)
+		return f(m[1], m[2])
+	})
+}
+
+// Endnote is a cited note with its number in reading order.
+type Endnote struct {
+	Note
+	N       int
+	Section int // Index of the section that cites it
+}
+
+// Endnotes numbers the notes sections cite, in the order they are first
+// cited, the way a book gathers them at its end. Notes never cited are
+// left out.
+func Endnotes(sections []Section) []Endnote {
+	var out []Endnote
+	seen := map[string]bool{}
+	for i, s := range sections {
+		for _, line := range s.Lines {
+			for _, m := range noteRefPattern.FindAllStringSubmatch(line, -1) {
+				key := strconv.Itoa(i) + "\x00" + m[1]
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				for _, n := range s.Notes {
+					if n.ID == m[1] {
+						out = append(out, Endnote{Note: n, N: len(out) + 1, Section: i})
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// EndnoteNumber finds the number of section i's note id, 0 if uncited.
+func EndnoteNumber(notes []Endnote, i int, id string) int {
+	for _, n := range notes {
+		if n.Section == i && n.ID == id {
+			return n.N
+		}
+	}
+	return 0
+}
+
+// endnotes is the invocation's own notes, numbered once.
+var endnotes = sync.OnceValue(func() []Endnote {
+	return Endnotes(sections)
+})
+
+// Superscript writes n in superscript digits, as a page marks a note.
+func Superscript(n int) string {
+	const digits = "⁰¹²³⁴⁵⁶⁷⁸⁹"
+	var b strings.Builder
+	for _, d := range strconv.Itoa(n) {
+		b.WriteString(string([]rune(digits)[d-'0']))
+	}
+	return b.String()
+}
+
+// WrapLinks wraps markdown text to width for the terminal: with links
+// on, each link's label opens its URL when clicked; otherwise the URL
+// follows in angle brackets. Links are made after wrapping, so the
+// escapes never count against the measure, and a label broken across
+// lines is linked on each of them.
+func WrapLinks(text string, width int, links bool) []string {
+	if !links {
+		return layout.Wrap(ReplaceLinks(text, func(label, url string) string {
+			return label + " <" + url + ">"
+		}), width)
+	}
+
+	// The plain text, and which link each of its runes belongs to
+	var plain []rune
+	var owner []int
+	var urls []string
+	at := 0
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		for _, r := range text[at:m[0]] {
+			plain, owner = append(plain, r), append(owner, -1)
+		}
+		for _, r := range text[m[2]:m[3]] {
+			plain, owner = append(plain, r), append(owner, len(urls))
+		}
+		urls = append(urls, text[m[4]:m[5]])
+		at = m[1]
+	}
+	for _, r := range text[at:] {
+		plain, owner = append(plain, r), append(owner, -1)
+	}
+
+	lines := layout.Wrap(string(plain), width)
+	pos := 0
+	space := func(r rune) bool { return unicode.IsSpace(r) }
+	for i, line := range lines {
+		var b strings.Builder
+		var piece []rune
+		cur := -1
+		flush := func() {
+			if cur >= 0 {
+				b.WriteString(theme.Hyperlink(urls[cur], string(piece)))
+			} else {
+				b.WriteString(string(piece))
+			}
+			piece = piece[:0]
+		}
+		for _, r := range line {
+			// Follow the wrapped line through the plain text: spaces
+			// stand for any run of them, and a hyphen the wrap added
+			// goes with the rune before it
+			id := -1
+			switch {
+			case r == ' ':
+				if pos < len(plain) && space(plain[pos]) {
+					id = owner[pos]
+				}
+				for pos < len(plain) && space(plain[pos]) {
+					pos++
+				}
+			default:
+				for pos < len(plain) && space(plain[pos]) {
+					pos++
+				}
+				if pos < len(plain) && plain[pos] == r {
+					id = owner[pos]
+					pos++
+				} else if pos > 0 {
+					id = owner[pos-1]
+				}
+			}
+			if id != cur {
+				flush()
+				cur = id
+			}
+			piece = append(piece, r)
+		}
+		flush()
+		lines[i] = b.String()
+	}
+	return lines
+}
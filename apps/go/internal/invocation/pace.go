@@ -0,0 +1,143 @@
+package invocation
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reading speed bounds, in words per minute. The default reveals the
+// invocation's average line in about the 750ms every line once took.
+const (
+	defaultWPM = 500
+	minWPM     = 120
+	maxWPM     = 1500
+
+	paceWeight = 0.2 // How far one sample moves the learned speed
+
+	minLineDelay = 300 * time.Millisecond
+	maxLineDelay = 5 * time.Second
+)
+
+// Pace is a practitioner's learned reading speed. It is measured from
+// how soon they press space to skip ahead during a reveal, and how long
+// they stay with a section once it is shown, and it sets how long each
+// line waits for the next.
+type Pace struct {
+	WPM     float64   `json:"wpm"`
+	Samples int       `json:"samples"`
+	Updated time.Time `json:"updated,omitzero"`
+}
+
+// DefaultPace is the speed of a practitioner not yet measured.
+func DefaultPace() Pace {
+	return Pace{WPM: defaultWPM}
+}
+
+// LoadPace reads a learned pace; a missing or broken file is the
+// default.
+func LoadPace(path string) Pace {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DefaultPace()
+	}
+	var p Pace
+	if json.Unmarshal(data, &p) != nil || p.WPM < minWPM || p.WPM > maxWPM {
+		return DefaultPace()
+	}
+	return p
+}
+
+// SavePace writes p to path, replacing what was there.
+func SavePace(path string, p Pace) error {
+	return writeJSON(path, p)
+}
+
+// lineDelay is how long body line j of section i stays the newest
+// before the next appears: its reading time at this pace, or a
+// paragraph break's pause scaled to the same rhythm.
+func (p Pace) lineDelay(i, j int) time.Duration {
+	wpm := p.wpm()
+	if sections[i].Lines[j] == "" {
+		return time.Duration(float64(paragraphPause) * defaultWPM / wpm)
+	}
+	d := time.Duration(float64(bodyWords()[i][j]) / wpm * float64(time.Minute))
+	return max(minLineDelay, min(d, maxLineDelay))
+}
+
+// learn folds in a sample: n words read in d. A sample slower than a
+// third of the current pace is the practitioner stepping away, not
+// reading, and is ignored. It reports whether p changed.
+func (p *Pace) learn(n int, d time.Duration) bool {
+	if n == 0 || d <= 0 {
+		return false
+	}
+	sample := float64(n) / d.Minutes()
+	if sample < p.wpm()/3 {
+		return false
+	}
+	sample = max(minWPM, min(sample, maxWPM))
+	p.WPM = p.wpm()*(1-paceWeight) + sample*paceWeight
+	p.Samples++
+	p.Updated = time.Now()
+	return true
+}
+
+func (p Pace) wpm() float64 {
+	if p.WPM < minWPM {
+		return defaultWPM
+	}
+	return p.WPM
+}
+
+// words counts the words of a line as read: markers and note
+// references aside.
+func words(line string) int {
+	return len(strings.Fields(StripNoteRefs(strings.ReplaceAll(line, "**", ""))))
+}
+
+// bodyWords is the word count of every body line by section, counted
+// once: the status bar adds up the delays of the rest every frame.
+var bodyWords = sync.OnceValue(func() [][]int {
+	counts := make([][]int, len(sections))
+	for i, s := range sections {
+		counts[i] = make([]int, len(s.Lines))
+		for j, line := range s.Lines {
+			counts[i][j] = words(line)
+		}
+	}
+	return counts
+})
+
+// sectionWords counts the words of section i, key line included.
+func sectionWords(i int) int {
+	n := words(sections[i].KeyLine)
+	for _, line := range sections[i].Lines {
+		n += words(line)
+	}
+	return n
+}
+
+// learnPace folds a sample into the model's pace. It is saved with the
+// place, when the section changes or the practitioner leaves, rather
+// than on every key.
+func (m *Model) learnPace(n int, d time.Duration) {
+	if m.pace.learn(n, d) {
+		m.paceUnsaved = true
+	}
+}
+
+// savePace writes the pace out if it has learned anything since it was
+// last written.
+func (m *Model) savePace() {
+	if !m.paceUnsaved || m.pacePath == "" {
+		return
+	}
+	if err := SavePace(m.pacePath, m.pace); err != nil {
+		progressLog.Printf("PACE %v", err)
+		return
+	}
+	m.paceUnsaved = false
+}
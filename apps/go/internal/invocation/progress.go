@@ -0,0 +1,122 @@
+package invocation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Progress is where the practitioner left the invocation. The section
+// is kept by title, so a reordered or extended text still finds it.
+type Progress struct {
+	Section string    `json:"section"`
+	Line    int       `json:"line"` // Body lines revealed
+	Time    time.Time `json:"time"`
+}
+
+// LoadProgress reads saved progress. A missing file, or a section no
+// longer in the text, is no progress.
+func LoadProgress(path string) (Progress, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Progress{}, false
+	}
+	var p Progress
+	if json.Unmarshal(data, &p) != nil || p.sectionIndex() < 0 {
+		return Progress{}, false
+	}
+	return p, true
+}
+
+// sectionIndex finds the saved section, or -1.
+func (p Progress) sectionIndex() int {
+	for i, s := range sections {
+		if s.Title == p.Section {
+			return i
+		}
+	}
+	return -1
+}
+
+// SaveProgress writes p to path, replacing what was there.
+func SaveProgress(path string, p Progress) error {
+	return writeJSON(path, p)
+}
+
+// writeJSON replaces the file at path with v, never leaving it half
+// written.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// A temp file of its own, so two sessions saving at once can't
+	// rename each other's half-written file into place
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ClearProgress forgets saved progress, once the invocation is complete.
+func ClearProgress(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// NewFromProgress opens the invocation where p left it, with the
+// section shown whole.
+func NewFromProgress(r *lipgloss.Renderer, p Progress, opts Options) Model {
+	m := NewAtSection(r, max(0, p.sectionIndex()), opts)
+	return m.revealAll()
+}
+
+// NewAtLine opens the invocation with section sectionIndex shown whole,
+// scrolled to its body line, or to its head when line is negative.
+func NewAtLine(r *lipgloss.Renderer, sectionIndex, line int, opts Options) Model {
+	m := NewAtSection(r, sectionIndex, opts).revealAll()
+	m.jumping, m.jumpLine = true, line
+	return m
+}
+
+// saveProgress records the current section, when there is a file to
+// record it in. The opening and closing are no place to come back to.
+func (m Model) saveProgress() {
+	if m.progressPath == "" {
+		return
+	}
+	switch m.phase {
+	case phaseOpening, phaseFarewell:
+		return
+	case phaseClosing:
+		if err := ClearProgress(m.progressPath); err != nil {
+			progressLog.Printf("PROGRESS %v", err)
+		}
+		return
+	}
+	err := SaveProgress(m.progressPath, Progress{
+		Section: sections[m.sectionIndex].Title,
+		Line:    m.lineIndex,
+		Time:    time.Now(),
+	})
+	if err != nil {
+		progressLog.Printf("PROGRESS %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package invocation
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/layout"
+)
+
+// farewellFade is the opacity of each frame of the exit screen: it
+// fades in, holds, and fades out before the program ends.
+var farewellFade = []int{0, 1, 2, 3, 3, 3, 3, 3, 3, 3, 3, 2, 1, 0}
+
+const farewellFrame = 150 * time.Millisecond
+
+type farewellTickMsg struct{}
+
+func farewellTick() tea.Cmd {
+	return tea.Tick(farewellFrame, func(time.Time) tea.Msg {
+		return farewellTickMsg{}
+	})
+}
+
+// quit answers the quit key. Mid-section it asks first; from the
+// opening or closing it goes straight to the exit screen. ctrl+c
+// never asks, but still saves.
+func (m Model) quit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlC {
+		m.saveProgress()
+		m.savePace()
+		return m, tea.Quit
+	}
+	if m.phase == phaseOpening || m.phase == phaseClosing {
+		return m.farewell()
+	}
+	m.confirming = true
+	return m, nil
+}
+
+// updateConfirm answers the quit dialog: y, enter or the quit key
+// again leave; anything else stays.
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.confirming = false
+	switch {
+	case msg.Type == tea.KeyCtrlC:
+		return m.quit(msg)
+	case msg.String() == "y", msg.String() == "Y", msg.Type == tea.KeyEnter, key.Matches(msg, m.keys.Quit):
+		return m.farewell()
+	}
+	return m, nil
+}
+
+// farewell saves the practitioner's place and starts the exit screen.
+func (m Model) farewell() (tea.Model, tea.Cmd) {
+	m.saveProgress()
+	m.savePace()
+	m.phase = phaseFarewell
+	m.farewellFrame = 0
+	return m, farewellTick()
+}
+
+func (m Model) handleFarewellTick() (tea.Model, tea.Cmd) {
+	if m.phase != phaseFarewell {
+		return m, nil
+	}
+	m.farewellFrame++
+	if m.farewellFrame >= len(farewellFade) {
+		return m, tea.Quit
+	}
+	return m, farewellTick()
+}
+
+// farewellLines is the exit screen at its current frame's opacity.
+func (m Model) farewellLines() string {
+	body, bold := m.fadeStyles(farewellFade[min(m.farewellFrame, len(farewellFade)-1)])
+	return bold.Render("॥ ॐ ॥") + "\n\n\n" + body.Render("You are a god in training.")
+}
+
+// viewConfirm is the quit dialog, over the page.
+func (m Model) viewConfirm() string {
+	s := m.styles
+	body := lipgloss.JoinVertical(lipgloss.Center,
+		s.KeyLine.Render("Leave the invocation?"),
+		"",
+		s.Dim.Render(strings.Join(layout.Wrap("Your place in "+sections[m.sectionIndex].Title+" is kept.", max(10, m.width-8)), "\n")),
+		"",
+		s.Prompt.Render("y leave · n stay"),
+	)
+	box := s.Body.
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(m.theme.Dim).
+		Padding(1, 2).
+		Render(body)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
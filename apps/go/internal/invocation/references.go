@@ -0,0 +1,69 @@
+package invocation
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/gorkolas/cybertantra/assets"
+)
+
+// Reference is a text recommended alongside the invocation.
+type Reference struct {
+	Title  string
+	Author string
+	About  string // What it offers, in a line or two
+	Links  []Link
+}
+
+// Link is a markdown link, [Label](URL).
+type Link struct {
+	Label string
+	URL   string
+}
+
+// Reading is the manifesto's recommended reading: what it is for, then
+// each text.
+var Reading = sync.OnceValues(func() (string, []Reference) {
+	return ParseReading(markdownSection(assets.Manifesto, "Recommended Reading"))
+})
+
+// markdownSection is the part of md under the "## title" heading, up to
+// the next heading of that level.
+func markdownSection(md, title string) string {
+	_, rest, ok := strings.Cut(md, "\n## "+title+"\n")
+	if !ok {
+		return ""
+	}
+	if i := strings.Index(rest, "\n## "); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest
+}
+
+// ParseReading reads a reading list in the document's markdown: a
+// paragraph on the list, then each text under "### Title — Author",
+// described in italics and linked from "→" lines. Arrow lines without
+// a markdown link, such as local paths, are left out.
+func ParseReading(md string) (intro string, refs []Reference) {
+	var about []string
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", line == "---", strings.HasPrefix(line, "## "):
+		case strings.HasPrefix(line, "### "):
+			title, author, _ := strings.Cut(strings.TrimPrefix(line, "### "), " — ")
+			refs = append(refs, Reference{Title: strings.TrimSpace(title), Author: strings.TrimSpace(author)})
+		case len(refs) == 0:
+			about = append(about, line)
+		case strings.HasPrefix(line, "→"):
+			r := &refs[len(refs)-1]
+			for _, m := range linkPattern.FindAllStringSubmatch(line, -1) {
+				r.Links = append(r.Links, Link{Label: m[1], URL: m[2]})
+			}
+		default:
+			r := &refs[len(refs)-1]
+			r.About = strings.TrimSpace(r.About + " " + strings.Trim(line, "*_"))
+		}
+	}
+	return strings.Join(about, " "), refs
+}
@@ -0,0 +1,37 @@
+package invocation
+
+import (
+	"strings"
+
+	"github.com/gorkolas/cybertantra/assets"
+)
+
+func init() {
+	askReflections(sections, ParseReflections(assets.Reflections))
+}
+
+// ParseReflections reads reflection questions in markdown, each under
+// the "## Title" of the section it follows, keyed by that title. Text
+// before the first such heading is left out.
+func ParseReflections(md string) map[string]string {
+	prompts := make(map[string]string)
+	title := ""
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "## "):
+			title = strings.TrimSpace(strings.TrimPrefix(line, "## "))
+		case title == "", line == "":
+		default:
+			prompts[title] = strings.TrimSpace(prompts[title] + " " + line)
+		}
+	}
+	return prompts
+}
+
+// askReflections gives each section the question written for it.
+func askReflections(sections []Section, prompts map[string]string) {
+	for i := range sections {
+		sections[i].Prompt = prompts[sections[i].Title]
+	}
+}
@@ -0,0 +1,14 @@
+package invocation
+
+import "testing"
+
+func TestEverySectionAsks(t *testing.T) {
+	for _, s := range Sections() {
+		if s.Prompt == "" {
+			t.Errorf("section %q has no reflection question", s.Title)
+		}
+	}
+	if got := ParseReflections("intro\n## A\n\nfirst\nsecond\n## B\n")["A"]; got != "first second" {
+		t.Errorf("ParseReflections joined %q, want %q", got, "first second")
+	}
+}
@@ -0,0 +1,124 @@
+package invocation
+
+import (
+	"io"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// TestViewAfterChange checks the cache lets nothing stale through: a
+// reader that has drawn a frame, then been resized or re-themed, draws
+// what a reader started at that size and theme would.
+func TestViewAfterChange(t *testing.T) {
+	other := theme.Next(theme.Builtin(), theme.Default())
+
+	m := benchModel(120, 40)
+	_ = m.View()
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 50, Height: 30})
+	m = next.(Model)
+	if got, want := m.View(), benchModel(50, 30).View(); got != want {
+		t.Errorf("view after resize differs from a fresh one:\n%s\nwant\n%s", got, want)
+	}
+
+	next, _ = m.Update(theme.ChangedMsg{Theme: other})
+	m = next.(Model)
+	if got, want := m.View(), revealedModel(50, 30, Options{Theme: other}).View(); got != want {
+		t.Errorf("view after a theme change differs from a fresh one:\n%s\nwant\n%s", got, want)
+	}
+}
+
+// benchModel is a truecolor reader w×h at the longest section, every
+// line revealed, as a practitioner sees it before pressing space.
+func benchModel(w, h int) Model {
+	return revealedModel(w, h, Options{})
+}
+
+// revealedModel is benchModel with opts.
+func revealedModel(w, h int, opts Options) Model {
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.TrueColor)
+
+	longest := 0
+	for i, s := range sections {
+		if len(s.Lines) > len(sections[longest].Lines) {
+			longest = i
+		}
+	}
+	m := NewAtSection(r, longest, opts)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: w, Height: h})
+	m = next.(Model)
+
+	sec := sections[longest]
+	m.phase = phaseWaitingForNext
+	m.charIndex = len(sec.KeyLine)
+	m.lineIndex = len(sec.Lines)
+	m.lineOpacity = make([]int, len(sec.Lines))
+	for i := range m.lineOpacity {
+		m.lineOpacity[i] = 3
+	}
+	m, _ = m.layout()
+	return m
+}
+
+// BenchmarkView is the frame Bubble Tea draws after every message.
+func BenchmarkView(b *testing.B) {
+	for _, size := range []struct {
+		name string
+		w, h int
+	}{{"desktop", 120, 40}, {"phone", 40, 24}} {
+		b.Run(size.name, func(b *testing.B) {
+			m := benchModel(size.w, size.h)
+			b.ReportAllocs()
+			for b.Loop() {
+				_ = m.View()
+			}
+		})
+	}
+}
+
+// BenchmarkFadeFrame is one fade tick of the reveal: the last line
+// brightening while the rest hold, then the redraw.
+func BenchmarkFadeFrame(b *testing.B) {
+	m := benchModel(120, 40)
+	last := len(m.lineOpacity) - 1
+	b.ReportAllocs()
+	for b.Loop() {
+		m.lineOpacity[last] = 0
+		m.phase = phaseBodyReveal
+		next, _ := m.Update(fadeTickMsg{m.fade})
+		_ = next.(Model).View()
+	}
+}
+
+// BenchmarkScrollFrame is one frame of smooth scrolling, where nothing
+// but the offset moves.
+func BenchmarkScrollFrame(b *testing.B) {
+	m := benchModel(40, 24)
+	b.ReportAllocs()
+	for b.Loop() {
+		m.viewport.SetYOffset(0)
+		m.scrollTarget = m.maxOffset()
+		next, _ := m.Update(scrollTickMsg{m.scroll})
+		_ = next.(Model).View()
+	}
+}
+
+// BenchmarkResize redraws after the terminal changes width, when every
+// cached line has to be rendered again.
+func BenchmarkResize(b *testing.B) {
+	m := benchModel(120, 40)
+	widths := []int{120, 100}
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		next, _ := m.Update(tea.WindowSizeMsg{Width: widths[i%2], Height: 40})
+		m = next.(Model)
+		_ = m.View()
+		i++
+	}
+}
@@ -0,0 +1,261 @@
+package invocation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/layout"
+)
+
+const (
+	wheelStep    = 3                     // Lines one notch of the mouse wheel scrolls
+	scrollGutter = 2                     // Columns kept right of the page for the scrollbar
+	scrollFrame  = 16 * time.Millisecond // Smooth scrolling's frame time
+)
+
+type scrollTickMsg struct{ gen uint64 }
+
+func (m Model) scrollTick() tea.Cmd {
+	gen := m.scroll
+	return tea.Tick(scrollFrame, func(time.Time) tea.Msg {
+		return scrollTickMsg{gen}
+	})
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	before := m.scene()
+	next, cmd := m.update(msg)
+	m = next.(Model)
+	if m.scene() != before {
+		// A new page starts at its top, following the reveal
+		m.viewport.SetYOffset(0)
+		m.scrollTarget = 0
+		m.follow = true
+	}
+	m, scroll := m.layout()
+	return m, tea.Batch(cmd, scroll)
+}
+
+// scene identifies what is on the page: the opening, a section, its
+// reflection, or the closing. Moving to another resets the scroll.
+func (m Model) scene() int {
+	switch m.phase {
+	case phaseOpening:
+		return -1
+	case phaseClosing:
+		return -2
+	case phaseReflection:
+		return len(sections) + m.sectionIndex
+	case phaseFarewell:
+		return -3
+	}
+	return m.sectionIndex
+}
+
+// pageWidth is the width content is centred in, leaving the gutter.
+func (m Model) pageWidth() int {
+	return max(m.width, layout.MinWidth) - scrollGutter
+}
+
+// pageStep is how far page up and down move: a screen, less two lines
+// of overlap to keep the reader's place.
+func (m Model) pageStep() int {
+	return max(1, m.viewport.Height-2)
+}
+
+// maxOffset is the furthest the viewport can scroll.
+func (m Model) maxOffset() int {
+	return max(0, len(m.lines)-m.viewport.Height)
+}
+
+// layout re-renders the content into the viewport and clamps where the
+// scroll is heading; while following, that is the newest line. It
+// starts a scroll animation when the viewport isn't there yet.
+func (m Model) layout() (Model, tea.Cmd) {
+	if !m.ready {
+		return m, nil
+	}
+	lines := m.contentLines()
+	m.viewport.Width = m.pageWidth()
+	m.viewport.Height = max(1, m.height-2-m.hudRows()) // Scroll indicator and key hint below, and the HUD
+	if !slices.Equal(lines, m.lines) {
+		// Cached lines compare by pointer, so a still page costs little
+		m.lines = lines
+		m.viewport.SetContent(strings.Join(lines, "\n"))
+	}
+
+	if m.jumping {
+		// The line lands a third of the way down, its lead-in above it
+		m.jumping, m.follow = false, false
+		m.scrollTarget = max(0, min(m.rowOf(m.jumpLine)-m.viewport.Height/3, m.maxOffset()))
+		m.viewport.SetYOffset(m.scrollTarget)
+	}
+	if m.follow {
+		m.scrollTarget = m.maxOffset()
+	}
+	m.scrollTarget = max(0, min(m.scrollTarget, m.maxOffset()))
+	if m.viewport.YOffset > m.maxOffset() {
+		m.viewport.SetYOffset(m.maxOffset())
+	}
+	if m.viewport.YOffset == m.scrollTarget || m.scrolling {
+		return m, nil
+	}
+	m.scrolling = true
+	return m, m.scrollTick()
+}
+
+// rowOf is the row body line i of the section starts on, as laid out;
+// a negative i is the section's head.
+func (m Model) rowOf(i int) int {
+	if i < 0 {
+		return 0
+	}
+	section := sections[m.sectionIndex]
+	row := len(m.head())
+	for j := 0; j < i && j < m.lineIndex && j < len(section.Lines) && j < len(m.lineOpacity); j++ {
+		if section.Lines[j] == "" {
+			row++
+		} else {
+			row += len(m.bodyLine(j, m.lineOpacity[j]))
+		}
+	}
+	return row
+}
+
+// scrollBy moves where the scroll is heading. Reaching the bottom
+// resumes following the reveal; scrolling away from it stops.
+func (m Model) scrollBy(n int) Model {
+	m.scrollTarget = max(0, min(m.scrollTarget+n, m.maxOffset()))
+	m.follow = m.scrollTarget >= m.maxOffset()
+	return m
+}
+
+// handleScrollTick eases the viewport a third of the way to its target
+// each frame, so long jumps glide and short ones snap.
+func (m Model) handleScrollTick() (tea.Model, tea.Cmd) {
+	diff := m.scrollTarget - m.viewport.YOffset
+	if diff == 0 {
+		m.scrolling = false
+		return m, nil
+	}
+	step := diff / 3
+	if step == 0 {
+		step = diff / max(1, abs(diff))
+	}
+	m.viewport.SetYOffset(m.viewport.YOffset + step)
+	return m, m.scrollTick()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+	if m.confirming {
+		return m.viewConfirm()
+	}
+	if m.footnotes {
+		return m.viewFootnotes()
+	}
+	full := m.pageWidth() + scrollGutter
+	blank := m.cache.blank + strings.Repeat(" ", scrollGutter)
+	gutter := blank[full-scrollGutter:]
+
+	// One builder for the whole frame: lines are already padded to the
+	// page, so they are copied straight out rather than through the
+	// viewport's own View
+	var b strings.Builder
+	b.Grow((m.viewport.Height + 4) * (len(blank) + 16))
+	hud := m.hudRows()
+	if hud > 0 {
+		b.WriteString(m.hudHeader(full))
+		b.WriteString("\n")
+	}
+	if len(m.lines) <= m.viewport.Height {
+		// Fits: centre it, no scrolling needed
+		top := (m.viewport.Height - len(m.lines)) / 2
+		for i := 0; i < top; i++ {
+			b.WriteString(blank)
+			b.WriteString("\n")
+		}
+		for _, line := range m.lines {
+			b.WriteString(line)
+			b.WriteString(gutter)
+			b.WriteString("\n")
+		}
+		for i := top + len(m.lines); i < m.viewport.Height+1; i++ {
+			b.WriteString(blank)
+			b.WriteString("\n")
+		}
+	} else {
+		bar := m.scrollbar()
+		top := m.viewport.YOffset
+		for i, line := range m.lines[top:min(top+m.viewport.Height, len(m.lines))] {
+			b.WriteString(line)
+			b.WriteString(" ")
+			b.WriteString(bar[i])
+			b.WriteString("\n")
+		}
+		b.WriteString(m.scrollIndicator(full) + "\n")
+	}
+	if hud > 1 {
+		b.WriteString(m.hudFooter(full))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.footer(full))
+	return b.String()
+}
+
+// scrollbar draws the track beside the viewport, one cell per row,
+// with a thumb sized to the share of the content on screen.
+func (m Model) scrollbar() []string {
+	h := m.viewport.Height
+	total := len(m.lines)
+	thumb := max(1, h*h/total)
+	top := 0
+	if maxOff := m.maxOffset(); maxOff > 0 {
+		top = (m.viewport.YOffset*(h-thumb) + maxOff/2) / maxOff
+	}
+	c := m.cache
+	if c.thumb == "" {
+		c.thumb, c.track = m.styles.KeyLine.Render("┃"), m.styles.Dim.Render("│")
+	}
+	bar := make([]string, h)
+	for i := range bar {
+		if i >= top && i < top+thumb {
+			bar[i] = c.thumb
+		} else {
+			bar[i] = c.track
+		}
+	}
+	return bar
+}
+
+// scrollIndicator says how far through the page the reader is, with
+// arrows toward whatever is still hidden.
+func (m Model) scrollIndicator(w int) string {
+	text := fmt.Sprintf("%d%%", int(m.viewport.ScrollPercent()*100+0.5))
+	if !m.viewport.AtTop() {
+		text = "↑ " + text
+	}
+	if !m.viewport.AtBottom() {
+		text += " ↓"
+	}
+	mark, ok := m.cache.marks[text]
+	if !ok {
+		mark = lipgloss.PlaceHorizontal(w, lipgloss.Center, m.styles.Dim.Render(text))
+		m.cache.marks[text] = mark
+	}
+	return mark
+}
@@ -0,0 +1,201 @@
+package journal
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Line prefixes for entries stored encrypted. Plain entries are bare JSON.
+const (
+	prefixPassphrase = "enc1:" // XChaCha20-Poly1305 under a scrypt-derived key
+	prefixSealed     = "age1:" // age file encrypted to an X25519 recipient
+)
+
+var (
+	// ErrLocked is returned while an encrypted journal awaits its passphrase.
+	ErrLocked = errors.New("journal is locked")
+	// ErrSealed is returned when reading a sealed journal without its identity.
+	ErrSealed = errors.New("journal is sealed; only the key-holder can read it")
+	// ErrPassphrase is returned when a passphrase doesn't open the journal.
+	ErrPassphrase = errors.New("wrong passphrase")
+)
+
+// Cipher protects individual journal lines.
+type Cipher interface {
+	Seal(plain []byte) (string, error)
+	Open(line string) ([]byte, error)
+}
+
+// keyFile records how the passphrase key is derived. The check value is
+// a sealed known string, used to reject a wrong passphrase up front.
+type keyFile struct {
+	KDF   string `json:"kdf"`
+	Salt  []byte `json:"salt"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Check string `json:"check"`
+}
+
+const checkText = "every screen is an altar"
+
+func keyPath(dir string) string {
+	return filepath.Join(dir, "key.json")
+}
+
+// passphraseCipher is symmetric: whoever knows the passphrase reads and writes.
+type passphraseCipher struct {
+	key []byte
+}
+
+func deriveKey(passphrase string, kf keyFile) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), kf.Salt, kf.N, kf.R, kf.P, chacha20poly1305.KeySize)
+}
+
+func (c passphraseCipher) Seal(plain []byte) (string, error) {
+	aead, err := chacha20poly1305.NewX(c.key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plain, nil)
+	return prefixPassphrase + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c passphraseCipher) Open(line string) ([]byte, error) {
+	if !strings.HasPrefix(line, prefixPassphrase) {
+		return nil, fmt.Errorf("not a passphrase-encrypted line")
+	}
+	data, err := base64.StdEncoding.DecodeString(line[len(prefixPassphrase):])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.NewX(c.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("truncated entry")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+// sealedCipher encrypts to a public key. Without the identity it can
+// only append; Open fails with ErrSealed.
+type sealedCipher struct {
+	recipient age.Recipient
+	identity  age.Identity
+}
+
+func (c sealedCipher) Seal(plain []byte) (string, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, c.recipient)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(plain); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return prefixSealed + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func (c sealedCipher) Open(line string) ([]byte, error) {
+	if c.identity == nil {
+		return nil, ErrSealed
+	}
+	if !strings.HasPrefix(line, prefixSealed) {
+		return nil, fmt.Errorf("not a sealed line")
+	}
+	data, err := base64.StdEncoding.DecodeString(line[len(prefixSealed):])
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), c.identity)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// SealedDir is where the familiar's sealed journal lives inside the
+// practitioner's journal directory.
+const SealedDir = "familiar"
+
+func recipientPath(dir string) string {
+	return filepath.Join(dir, "recipient.txt")
+}
+
+// InitSealed creates a sealed journal in dir and returns the identity
+// that reads it. Only the public recipient is stored; hand the identity
+// to the familiar and don't keep a copy.
+func InitSealed(dir string) (string, error) {
+	if _, err := os.Stat(recipientPath(dir)); err == nil {
+		return "", fmt.Errorf("sealed journal already exists in %s", dir)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(recipientPath(dir), []byte(id.Recipient().String()+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// ParseIdentity reads an age identity from a key file's contents,
+// skipping comment lines.
+func ParseIdentity(text string) (age.Identity, error) {
+	ids, err := age.ParseIdentities(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	return ids[0], nil
+}
+
+// SealedCount returns the number of entries in the sealed journal under
+// dir, and whether one exists. Nothing is decrypted.
+func SealedCount(dir string) (int, bool) {
+	sealed := filepath.Join(dir, SealedDir)
+	if _, err := os.Stat(recipientPath(sealed)); err != nil {
+		return 0, false
+	}
+	j := &Journal{dir: sealed}
+	n, err := j.Count()
+	return n, err == nil
+}
+
+func readKeyFile(dir string) (keyFile, bool, error) {
+	data, err := os.ReadFile(keyPath(dir))
+	if os.IsNotExist(err) {
+		return keyFile{}, false, nil
+	}
+	if err != nil {
+		return keyFile{}, false, err
+	}
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return keyFile{}, false, err
+	}
+	return kf, true, nil
+}
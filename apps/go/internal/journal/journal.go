@@ -0,0 +1,476 @@
+// Package journal stores the practitioner's dated reflections.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"filippo.io/age"
+)
+
+// Entry is one reflection.
+type Entry struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Section string    `json:"section,omitempty"` // Invocation section it answers
+	Prompt  string    `json:"prompt,omitempty"`
+	Text    string    `json:"text"`
+	Tags    []string  `json:"tags,omitempty"`
+	Mood    int       `json:"mood,omitempty"` // 1 (heavy) to 5 (light); 0 unrated
+}
+
+var (
+	tagPattern = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_-]+)`)
+	// A line of its own: "mood: 4", "mood 4/5"
+	moodPattern = regexp.MustCompile(`(?im)^\s*mood:?\s*([1-5])(?:\s*/\s*5)?\s*$`)
+)
+
+// Journal is an append-only log of entries in dir/entries.jsonl, one
+// entry per line: bare JSON, or encrypted when a Cipher is in place.
+type Journal struct {
+	dir       string
+	mu        sync.Mutex
+	cipher    Cipher
+	encrypted bool // key.json present; needs Unlock before use
+	sealed    bool // recipient.txt present; append-only without the identity
+	recipient string
+}
+
+// Open prepares a journal rooted at dir. An encrypted journal starts
+// locked; a sealed journal can be appended to but not read.
+func Open(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	j := &Journal{dir: dir}
+
+	if data, err := os.ReadFile(recipientPath(dir)); err == nil {
+		j.recipient = strings.TrimSpace(string(data))
+		r, err := age.ParseX25519Recipient(j.recipient)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", recipientPath(dir), err)
+		}
+		j.sealed = true
+		j.cipher = sealedCipher{recipient: r}
+		return j, nil
+	}
+
+	_, ok, err := readKeyFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	j.encrypted = ok
+	if ok {
+		// Encrypt stopped after writing the key; finish its swap
+		if _, err := os.Stat(j.tmpPath()); err == nil {
+			if err := os.Rename(j.tmpPath(), j.path()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return j, nil
+}
+
+// Encrypted reports whether entries are protected by a passphrase.
+func (j *Journal) Encrypted() bool {
+	return j.encrypted
+}
+
+// Sealed reports whether entries are encrypted to someone else's key.
+func (j *Journal) Sealed() bool {
+	return j.sealed
+}
+
+// Locked reports whether an encrypted journal still needs its passphrase.
+func (j *Journal) Locked() bool {
+	return j.encrypted && j.cipher == nil
+}
+
+// Unlock derives the key from passphrase and checks it against key.json.
+func (j *Journal) Unlock(passphrase string) error {
+	kf, ok, err := readKeyFile(j.dir)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("journal is not encrypted")
+	}
+	key, err := deriveKey(passphrase, kf)
+	if err != nil {
+		return err
+	}
+	c := passphraseCipher{key: key}
+	if check, err := c.Open(kf.Check); err != nil || string(check) != checkText {
+		return ErrPassphrase
+	}
+	j.mu.Lock()
+	j.cipher = c
+	j.mu.Unlock()
+	return nil
+}
+
+// UnlockSealed lets the key-holder read a sealed journal.
+func (j *Journal) UnlockSealed(identity age.Identity) error {
+	if !j.sealed {
+		return fmt.Errorf("journal is not sealed")
+	}
+	x, ok := identity.(*age.X25519Identity)
+	if !ok || x.Recipient().String() != j.recipient {
+		return fmt.Errorf("identity does not match this journal's recipient")
+	}
+	j.mu.Lock()
+	j.cipher = sealedCipher{recipient: x.Recipient(), identity: x}
+	j.mu.Unlock()
+	return nil
+}
+
+// Encrypt protects a plain journal with a passphrase, rewriting every
+// existing entry under the new key.
+func (j *Journal) Encrypt(passphrase string) error {
+	if j.encrypted || j.sealed {
+		return fmt.Errorf("journal is already encrypted")
+	}
+	if passphrase == "" {
+		return fmt.Errorf("empty passphrase")
+	}
+
+	// Held throughout, so an entry added meanwhile isn't lost in the rewrite
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entries, err := j.entries()
+	if err != nil {
+		return err
+	}
+
+	kf := keyFile{KDF: "scrypt", Salt: make([]byte, 16), N: 1 << 15, R: 8, P: 1}
+	if _, err := rand.Read(kf.Salt); err != nil {
+		return err
+	}
+	key, err := deriveKey(passphrase, kf)
+	if err != nil {
+		return err
+	}
+	c := passphraseCipher{key: key}
+	if kf.Check, err = c.Seal([]byte(checkText)); err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	for i := len(entries) - 1; i >= 0; i-- {
+		data, err := json.Marshal(entries[i])
+		if err != nil {
+			return err
+		}
+		line, err := c.Seal(data)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	kdata, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Entries, key, then the swap: a crash before the key leaves the
+	// plain journal as it was, and one after it leaves the sealed
+	// entries in place for Open to finish moving.
+	if err := os.WriteFile(j.tmpPath(), []byte(buf.String()), 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(keyPath(j.dir), kdata, 0600); err != nil {
+		os.Remove(j.tmpPath())
+		return err
+	}
+	if err := os.Rename(j.tmpPath(), j.path()); err != nil {
+		return err
+	}
+	j.encrypted = true
+	j.cipher = c
+	return nil
+}
+
+// Dir is where the journal keeps its files.
+func (j *Journal) Dir() string {
+	return j.dir
+}
+
+func (j *Journal) path() string {
+	return filepath.Join(j.dir, "entries.jsonl")
+}
+
+// tmpPath is where Encrypt writes the rewritten entries before they
+// replace the journal.
+func (j *Journal) tmpPath() string {
+	return j.path() + ".tmp"
+}
+
+// Add stamps the entry with an ID, time and #tags found in its text,
+// then appends it.
+func (j *Journal) Add(e Entry) (Entry, error) {
+	e.Text = strings.TrimSpace(e.Text)
+	if e.Text == "" {
+		return e, fmt.Errorf("empty entry")
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.ID == "" {
+		e.ID = newID(e.Time)
+	}
+	e.Tags = mergeTags(e.Tags, ParseTags(e.Text))
+	if e.Mood == 0 {
+		e.Mood = ParseMood(e.Text)
+	}
+	if e.Mood < 0 || e.Mood > 5 {
+		return e, fmt.Errorf("mood must be 1 to 5")
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.Locked() {
+		return e, ErrLocked
+	}
+	line := data
+	if j.cipher != nil {
+		sealed, err := j.cipher.Seal(data)
+		if err != nil {
+			return e, err
+		}
+		line = []byte(sealed)
+	}
+
+	f, err := os.OpenFile(j.path(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return e, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return e, err
+	}
+	return e, f.Close()
+}
+
+// Count returns the number of entries without decrypting them.
+func (j *Journal) Count() (int, error) {
+	data, err := os.ReadFile(j.path())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > 0 {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Entries returns every entry, newest first. Once the journal is
+// encrypted or sealed, a line left bare is an error, not an entry: it
+// wasn't written by this journal.
+func (j *Journal) Entries() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.entries()
+}
+
+func (j *Journal) entries() ([]Entry, error) {
+	if j.Locked() {
+		return nil, ErrLocked
+	}
+
+	f, err := os.Open(j.path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4<<20)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		data := sc.Bytes()
+		if data[0] == '{' {
+			if j.encrypted || j.sealed {
+				return nil, fmt.Errorf("%s line %d: not encrypted", j.path(), n)
+			}
+		} else {
+			if j.cipher == nil {
+				return nil, ErrLocked
+			}
+			plain, err := j.cipher.Open(sc.Text())
+			if err != nil {
+				return nil, err
+			}
+			data = plain
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].Time.After(entries[b].Time)
+	})
+	return entries, nil
+}
+
+// ParseTags finds #hashtags in text, lower-cased and deduplicated.
+func ParseTags(text string) []string {
+	var tags []string
+	for _, m := range tagPattern.FindAllStringSubmatch(text, -1) {
+		tags = mergeTags(tags, []string{m[1]})
+	}
+	return tags
+}
+
+// ParseMood finds a "mood: N" line (N from 1 to 5) in text, or returns 0.
+func ParseMood(text string) int {
+	m := moodPattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	return int(m[1][0] - '0')
+}
+
+func mergeTags(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	out := make([]string, 0, len(a)+len(b))
+	for _, t := range append(a, b...) {
+		t = strings.ToLower(strings.TrimPrefix(t, "#"))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// Search filters entries. Words prefixed with # must all be tags of the
+// entry; other words must all appear in its text, section or prompt.
+func Search(entries []Entry, query string) []Entry {
+	var tags, words []string
+	for _, f := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(f, "#") && len(f) > 1 {
+			tags = append(tags, f[1:])
+		} else {
+			words = append(words, f)
+		}
+	}
+	if len(tags) == 0 && len(words) == 0 {
+		return entries
+	}
+
+	var out []Entry
+	for _, e := range entries {
+		if e.matches(tags, words) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (e Entry) matches(tags, words []string) bool {
+	for _, t := range tags {
+		found := false
+		for _, et := range e.Tags {
+			if et == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	hay := strings.ToLower(e.Text + "\n" + e.Section + "\n" + e.Prompt)
+	for _, w := range words {
+		if !strings.Contains(hay, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportMarkdown writes entries grouped by day, oldest first.
+func ExportMarkdown(w io.Writer, entries []Entry) error {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Time.Before(sorted[b].Time)
+	})
+
+	var b strings.Builder
+	b.WriteString("# Practice Journal\n")
+	day := ""
+	for _, e := range sorted {
+		if d := e.Time.Local().Format("2006-01-02"); d != day {
+			day = d
+			fmt.Fprintf(&b, "\n## %s\n", day)
+		}
+		b.WriteString("\n### ")
+		b.WriteString(e.Time.Local().Format("15:04"))
+		if e.Section != "" {
+			b.WriteString(" — ")
+			b.WriteString(e.Section)
+		}
+		b.WriteString("\n\n")
+		if e.Prompt != "" {
+			fmt.Fprintf(&b, "> %s\n\n", e.Prompt)
+		}
+		b.WriteString(e.Text)
+		b.WriteString("\n")
+		if e.Mood > 0 && ParseMood(e.Text) == 0 {
+			fmt.Fprintf(&b, "\nMood: %d/5\n", e.Mood)
+		}
+		if len(e.Tags) > 0 {
+			b.WriteString("\n")
+			for i, t := range e.Tags {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				b.WriteString("#" + t)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func newID(t time.Time) string {
+	buf := make([]byte, 4)
+	rand.Read(buf)
+	return t.UTC().Format("20060102T150405") + "-" + hex.EncodeToString(buf)
+}
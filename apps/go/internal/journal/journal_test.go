@@ -0,0 +1,80 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptedRejectsPlainLines(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Add(Entry{Text: "before"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Encrypt("mantra"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Add(Entry{Text: "after"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := j.Entries()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("entries = %d, %v; want 2", len(entries), err)
+	}
+
+	// A bare line slipped in beside the ciphertext is not read as an entry
+	f, err := os.OpenFile(j.path(), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"x","text":"planted"}` + "\n")
+	f.Close()
+	if _, err := j.Entries(); err == nil {
+		t.Fatal("encrypted journal read a plain line")
+	}
+}
+
+func TestOpenFinishesEncrypt(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Add(Entry{Text: "kept"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Encrypt("mantra"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stand the files back as a crash after the key was written leaves them
+	sealed, err := os.ReadFile(j.path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(j.tmpPath(), sealed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "entries.jsonl"), []byte(`{"id":"a","text":"kept"}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Unlock("mantra"); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := j.Entries()
+	if err != nil || len(entries) != 1 || entries[0].Text != "kept" {
+		t.Fatalf("entries = %+v, %v; want the one kept entry", entries, err)
+	}
+	if _, err := os.Stat(j.tmpPath()); !os.IsNotExist(err) {
+		t.Errorf("temp file still there: %v", err)
+	}
+}
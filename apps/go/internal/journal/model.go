@@ -0,0 +1,454 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+type mode int
+
+const (
+	modeList mode = iota
+	modeSearch
+	modeRead
+	modeWrite
+	modeUnlock
+)
+
+// Model is the journal view: dated entries, search, reading, writing
+// a free entry, and markdown export.
+type Model struct {
+	journal  *Journal
+	entries  []Entry // All entries, newest first
+	shown    []Entry // Entries matching the current query
+	cursor   int
+	offset   int
+	mode     mode
+	search   textinput.Model
+	editor   textarea.Model
+	passIn   textinput.Model
+	sealed   int  // Entries in the familiar's sealed journal
+	hasSeal  bool // Whether the familiar has a sealed journal
+	status   string
+	keys     keys.Map
+	help     help.Model
+	theme    theme.Theme
+	width    int
+	height   int
+	ready    bool
+	renderer *lipgloss.Renderer
+}
+
+func New(r *lipgloss.Renderer, j *Journal, th theme.Theme, km keys.Map) Model {
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "words or #tags"
+
+	editor := NewEditor()
+
+	passIn := textinput.New()
+	passIn.Prompt = "passphrase: "
+	passIn.EchoMode = textinput.EchoPassword
+	passIn.EchoCharacter = '•'
+
+	m := Model{
+		journal:  j,
+		search:   search,
+		editor:   editor,
+		passIn:   passIn,
+		keys:     km.Or(),
+		help:     keys.NewHelp(r, th),
+		theme:    th,
+		renderer: r,
+	}
+	m.sealed, m.hasSeal = SealedCount(j.Dir())
+	if j.Locked() {
+		m.mode = modeUnlock
+		m.passIn.Focus()
+		return m
+	}
+	m.reload()
+	return m
+}
+
+// NewEditor is the textarea shared by free entries and section reflections.
+func NewEditor() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = "Make the unconscious conscious…"
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.SetHeight(8)
+	return ta
+}
+
+// Capturing reports whether keystrokes are going into a text field,
+// so the parent must not treat them as navigation. The passphrase
+// prompt still lets esc through to leave the journal.
+func (m Model) Capturing() bool {
+	return m.mode == modeSearch || m.mode == modeWrite
+}
+
+// Resume picks up entries written since the journal was last shown,
+// such as reflections kept during the invocation.
+func (m Model) Resume() (tea.Model, tea.Cmd) {
+	if !m.journal.Locked() {
+		m.reload()
+	}
+	return m, nil
+}
+
+func (m *Model) reload() {
+	entries, err := m.journal.Entries()
+	if err != nil {
+		m.status = "journal: " + err.Error()
+		return
+	}
+	m.entries = entries
+	m.filter()
+}
+
+func (m *Model) filter() {
+	m.shown = Search(m.entries, m.search.Value())
+	m.moveCursor(0)
+}
+
+func (m Model) Init() tea.Cmd {
+	if m.mode == modeUnlock {
+		return textinput.Blink
+	}
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if changed, ok := msg.(theme.ChangedMsg); ok {
+		m.theme = changed.Theme
+		m.help = keys.NewHelp(m.renderer, m.theme)
+		return m, nil
+	}
+	if wsMsg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = wsMsg.Width
+		m.height = wsMsg.Height
+		m.ready = true
+		m.editor.SetWidth(m.contentWidth())
+		m.search.Width = m.contentWidth() - 2
+		return m, nil
+	}
+
+	// The wheel moves through the list
+	if mouse, ok := msg.(tea.MouseMsg); ok && mouse.Action == tea.MouseActionPress && m.mode == modeList {
+		switch mouse.Button {
+		case tea.MouseButtonWheelUp:
+			m.moveCursor(-1)
+		case tea.MouseButtonWheelDown:
+			m.moveCursor(1)
+		}
+		return m, nil
+	}
+
+	keyMsg, isKey := msg.(tea.KeyMsg)
+
+	switch m.mode {
+	case modeUnlock:
+		if isKey {
+			switch keyMsg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "enter":
+				if err := m.journal.Unlock(m.passIn.Value()); err != nil {
+					m.status = err.Error()
+					m.passIn.SetValue("")
+					return m, nil
+				}
+				m.passIn.SetValue("")
+				m.passIn.Blur()
+				m.status = ""
+				m.mode = modeList
+				m.reload()
+				return m, nil
+			case "esc":
+				// Not capturing: the parent takes us back to the menu
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.passIn, cmd = m.passIn.Update(msg)
+		return m, cmd
+
+	case modeSearch:
+		if isKey {
+			switch keyMsg.String() {
+			case "enter":
+				m.search.Blur()
+				m.mode = modeList
+				return m, nil
+			case "esc":
+				m.search.SetValue("")
+				m.search.Blur()
+				m.mode = modeList
+				m.filter()
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.search, cmd = m.search.Update(msg)
+		m.filter()
+		return m, cmd
+
+	case modeWrite:
+		if isKey {
+			switch keyMsg.String() {
+			case "ctrl+s":
+				if _, err := m.journal.Add(Entry{Text: m.editor.Value()}); err != nil {
+					m.status = err.Error()
+				} else {
+					m.status = "Entry saved."
+				}
+				m.editor.Reset()
+				m.editor.Blur()
+				m.mode = modeList
+				m.reload()
+				return m, nil
+			case "esc":
+				m.editor.Blur()
+				m.mode = modeList
+				m.status = "Draft kept."
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.editor, cmd = m.editor.Update(msg)
+		return m, cmd
+
+	case modeRead:
+		if isKey {
+			switch {
+			case key.Matches(keyMsg, m.keys.Quit):
+				return m, tea.Quit
+			case key.Matches(keyMsg, m.keys.Back):
+				m.mode = modeList
+			}
+		}
+		return m, nil
+	}
+
+	if !isKey {
+		return m, nil
+	}
+	m.status = ""
+	switch {
+	case key.Matches(keyMsg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(keyMsg, m.keys.Up):
+		m.moveCursor(-1)
+	case key.Matches(keyMsg, m.keys.Down):
+		m.moveCursor(1)
+	case key.Matches(keyMsg, m.keys.Select):
+		if m.cursor < len(m.shown) {
+			m.mode = modeRead
+		}
+	case key.Matches(keyMsg, m.keys.Search):
+		m.mode = modeSearch
+		return m, m.search.Focus()
+	case key.Matches(keyMsg, m.keys.Write):
+		m.mode = modeWrite
+		return m, m.editor.Focus()
+	case key.Matches(keyMsg, m.keys.Export):
+		path, err := m.export()
+		if err != nil {
+			m.status = "export: " + err.Error()
+		} else {
+			m.status = "Exported to " + path
+		}
+	}
+	return m, nil
+}
+
+// moveCursor moves the list cursor by delta, scrolling to keep it shown.
+func (m *Model) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.shown)-1))
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+// export writes the currently shown entries as markdown in the journal dir.
+func (m Model) export() (string, error) {
+	path := filepath.Join(m.journal.Dir(), "journal-"+time.Now().Format("2006-01-02")+".md")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if err := ExportMarkdown(f, m.shown); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+func (m Model) contentWidth() int {
+	_, x := m.padding()
+	return max(10, min(m.width-2*x, 72))
+}
+
+func (m Model) listHeight() int {
+	y, _ := m.padding()
+	return max(1, m.height-6-2*y)
+}
+
+// padding is the space around the view, dropped to a column on a
+// narrow or short terminal.
+func (m Model) padding() (y, x int) {
+	if layout.Narrow(m.width) || layout.Short(m.height) {
+		return 0, 1
+	}
+	return 1, 2
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	th := m.theme
+	quiet := theme.Degraded(r)
+	titleStyle := r.NewStyle().Foreground(th.Accent).Bold(true)
+	headStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+	textStyle := r.NewStyle().Foreground(th.Text)
+	brightStyle := r.NewStyle().Foreground(th.Bright)
+	cursorStyle := r.NewStyle().Foreground(th.Title).Bold(true)
+	tagStyle := r.NewStyle().Foreground(th.Highlight)
+	promptStyle := r.NewStyle().Foreground(th.Faded).Faint(quiet).Italic(true)
+
+	w := m.contentWidth()
+	help := m.help
+	help.Width = w
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("॥ JOURNAL ॥"))
+	n := len(m.entries)
+	if m.journal.Locked() {
+		n, _ = m.journal.Count()
+	}
+	head := fmt.Sprintf("  %d entries", n)
+	if m.journal.Encrypted() {
+		head += " · encrypted"
+	}
+	if m.hasSeal {
+		head += fmt.Sprintf(" · %d sealed in the familiar's journal", m.sealed)
+	}
+	b.WriteString(headStyle.Render(head))
+	b.WriteString("\n\n")
+
+	if m.journal.Locked() {
+		b.WriteString(textStyle.Render("This journal is encrypted."))
+		b.WriteString("\n\n")
+		b.WriteString(m.passIn.View())
+		b.WriteString("\n\n")
+		if m.status != "" {
+			b.WriteString(promptStyle.Render(m.status))
+			b.WriteString("\n")
+		}
+		b.WriteString(headStyle.Render("enter unlock · esc menu"))
+		return r.NewStyle().Padding(m.padding()).Render(b.String())
+	}
+
+	switch m.mode {
+	case modeWrite:
+		b.WriteString(m.editor.View())
+		b.WriteString("\n\n")
+		b.WriteString(headStyle.Width(w).Render("ctrl+s save · esc back · #tags and a \"mood: 1-5\" line are picked up"))
+
+	case modeRead:
+		e := m.shown[m.cursor]
+		head := e.Time.Local().Format("Monday 2006-01-02 15:04")
+		if e.Section != "" {
+			head += " · " + e.Section
+		}
+		if e.Mood > 0 {
+			head += fmt.Sprintf(" · mood %d/5", e.Mood)
+		}
+		b.WriteString(headStyle.Render(head))
+		b.WriteString("\n\n")
+		if e.Prompt != "" {
+			b.WriteString(promptStyle.Width(w).Render(e.Prompt))
+			b.WriteString("\n\n")
+		}
+		b.WriteString(brightStyle.Width(w).Render(e.Text))
+		b.WriteString("\n")
+		if len(e.Tags) > 0 {
+			b.WriteString("\n")
+			b.WriteString(tagStyle.Render("#" + strings.Join(e.Tags, " #")))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(help.ShortHelpView([]key.Binding{m.keys.Back, m.keys.Menu}))
+
+	default:
+		if m.mode == modeSearch || m.search.Value() != "" {
+			b.WriteString(m.search.View())
+			b.WriteString("\n\n")
+		}
+		if len(m.shown) == 0 {
+			if len(m.entries) == 0 {
+				b.WriteString(textStyle.Width(w).Render("No entries yet. Press " + m.keys.Write.Help().Key + " to write, or reflect after an invocation section."))
+			} else {
+				b.WriteString(textStyle.Render("Nothing matches."))
+			}
+			b.WriteString("\n")
+		}
+
+		h := m.listHeight()
+		for i := m.offset; i < len(m.shown) && i < m.offset+h; i++ {
+			e := m.shown[i]
+			first, _, _ := strings.Cut(e.Text, "\n")
+			label := e.Time.Local().Format("2006-01-02 15:04") + "  "
+			if e.Section != "" {
+				label += "[" + e.Section + "] "
+			}
+			row := label + first
+			if len([]rune(row)) > w-2 {
+				row = string([]rune(row)[:w-3]) + "…"
+			}
+			if i == m.cursor {
+				b.WriteString(cursorStyle.Render("► " + row))
+			} else {
+				b.WriteString(textStyle.Render("  " + row))
+			}
+			b.WriteString("\n")
+		}
+
+		b.WriteString("\n")
+		if m.status != "" {
+			b.WriteString(promptStyle.Render(m.status))
+			b.WriteString("\n")
+		}
+		if m.mode == modeSearch {
+			b.WriteString(headStyle.Render("enter keep filter · esc clear"))
+		} else {
+			b.WriteString(help.ShortHelpView(m.keys.JournalView().ShortHelp()))
+		}
+	}
+
+	return r.NewStyle().Padding(m.padding()).Render(b.String())
+}
@@ -0,0 +1,221 @@
+package keys
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+)
+
+// file is the on-disk form of the bindings: a preset, then the actions
+// it rebinds. Each listed action replaces the preset's keys outright.
+//
+//	preset = "vim"
+//
+//	[bind]
+//	advance = ["space", "n"]
+//	back = ["b"]
+type file struct {
+	Preset string              `json:"preset" toml:"preset"`
+	Bind   map[string][]string `json:"bind" toml:"bind"`
+}
+
+// Path is where the bindings live: keys.toml, or keys.json if that is
+// the one present.
+func Path() string {
+	if p := datadir.Path("keys.json"); exists(p) && !exists(datadir.Path("keys.toml")) {
+		return p
+	}
+	return datadir.Path("keys.toml")
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Load reads the bindings at path. A missing file is the defaults; a
+// broken one is the defaults and an error saying why.
+func Load(path string) (Map, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), err
+	}
+
+	var f file
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = toml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	m, err := f.apply()
+	if err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// apply starts from the preset and rebinds what the file lists.
+func (f file) apply() (Map, error) {
+	preset := strings.ToLower(strings.TrimSpace(f.Preset))
+	if preset == "" {
+		preset = "default"
+	}
+	base, ok := presets[preset]
+	if !ok {
+		return Map{}, fmt.Errorf("unknown preset %q (want default, vim or emacs)", f.Preset)
+	}
+	m := base()
+
+	names := make([]string, 0, len(f.Bind))
+	for name := range f.Bind {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a, ok := findAction(name)
+		if !ok {
+			return Map{}, fmt.Errorf("unknown action %q", name)
+		}
+		if len(f.Bind[name]) == 0 {
+			return Map{}, fmt.Errorf("%s: no keys", name)
+		}
+		keys := make([]string, len(f.Bind[name]))
+		for i, k := range f.Bind[name] {
+			pk, err := parse(k)
+			if err != nil {
+				return Map{}, fmt.Errorf("%s: %w", name, err)
+			}
+			keys[i] = pk
+		}
+		*a.field(&m) = bind(keys, a.desc)
+	}
+	return m, m.conflict()
+}
+
+// conflict reports a key that two actions claim on one screen, where
+// only the first would ever see it.
+func (m Map) conflict() error {
+	views := m.views()
+	for _, name := range slices.Sorted(maps.Keys(views)) {
+		owner := make(map[string]string)
+		for _, group := range views[name].Full {
+			for _, b := range group {
+				desc := b.Help().Desc
+				for _, k := range b.Keys() {
+					if other, ok := owner[k]; ok && other != desc {
+						return fmt.Errorf("%q is both %s and %s in the %s", display(k), other, desc, name)
+					}
+					owner[k] = desc
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findAction looks an action up by its keys.toml name.
+func findAction(name string) (action, bool) {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "_")
+	for _, a := range actions {
+		if a.name == name {
+			return a, true
+		}
+	}
+	return action{}, false
+}
+
+// parse reads a key as written in the file, "space" or "ctrl+d" or the
+// bubbletea name ("pgdown", "enter"), into the form bubbletea reports.
+// Names and modifiers are read in any case and modifiers in any order.
+// A single character keeps its own case, so "M" is shift+m, and
+// "shift+m" is written "M" too; after ctrl a letter is always lower
+// case. A key bubbletea can never report, like "shift+1" or
+// "ctrl+shift+m", is an error rather than a binding that never fires.
+func parse(k string) (string, error) {
+	written := k
+	k = strings.TrimSpace(k)
+	var alt, ctrl, shift bool
+	for {
+		mod, rest, ok := strings.Cut(k, "+")
+		if !ok || rest == "" {
+			break
+		}
+		switch strings.ToLower(mod) {
+		case "alt":
+			alt = true
+		case "ctrl":
+			ctrl = true
+		case "shift":
+			shift = true
+		default:
+			return "", fmt.Errorf("unknown modifier in %q", written)
+		}
+		k = rest
+	}
+
+	if utf8.RuneCountInString(k) == 1 {
+		r, _ := utf8.DecodeRuneInString(k)
+		switch {
+		case ctrl && shift:
+			return "", fmt.Errorf("%q: ctrl+shift is only reported on arrows, home and end", written)
+		case ctrl:
+			k = strings.ToLower(k)
+		case shift && !unicode.IsLetter(r):
+			return "", fmt.Errorf("%q: write the character shift makes instead", written)
+		case shift:
+			k = strings.ToUpper(k)
+			shift = false
+		}
+	} else {
+		k = strings.ToLower(k)
+		switch k {
+		case "space", "spc":
+			k = " "
+		case "pageup":
+			k = "pgup"
+		case "pagedown":
+			k = "pgdown"
+		case "return":
+			k = "enter"
+		case "escape":
+			k = "esc"
+		}
+		if shift && !shiftable[k] {
+			return "", fmt.Errorf("%q: shift is only reported on tab, arrows, home and end", written)
+		}
+	}
+
+	// bubbletea writes modifiers in this order
+	if shift {
+		k = "shift+" + k
+	}
+	if ctrl {
+		k = "ctrl+" + k
+	}
+	if alt {
+		k = "alt+" + k
+	}
+	return k, nil
+}
+
+// shiftable are the named keys bubbletea reports with shift held.
+var shiftable = map[string]bool{
+	"tab": true, "up": true, "down": true, "left": true, "right": true, "home": true, "end": true,
+}
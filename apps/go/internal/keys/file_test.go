@@ -0,0 +1,73 @@
+package keys
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadKeepsCase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.toml")
+	data := "[bind]\nmark_section = [\"M\"]\nadvance = [\"Space\", \"N\"]\nback = [\"CTRL+B\", \"Alt+B\", \"Shift+B\", \"Home\"]\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"mark_section", m.MarkSection.Keys(), []string{"M"}},
+		{"mark", m.Mark.Keys(), []string{"m"}},
+		{"advance", m.Advance.Keys(), []string{" ", "N"}},
+		{"back", m.Back.Keys(), []string{"ctrl+b", "alt+B", "B", "home"}},
+	} {
+		if !slices.Equal(c.got, c.want) {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestPresetsHaveNoConflicts(t *testing.T) {
+	for name, preset := range presets {
+		if err := preset().conflict(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestLoadRejectsConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.toml")
+	if err := os.WriteFile(path, []byte("[bind]\nhud = [\"m\"]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("m bound to both hud and highlight loaded without an error")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]string{
+		"shift+m":       "M",
+		"alt+shift+m":   "alt+M",
+		"Ctrl+Alt+X":    "alt+ctrl+x",
+		"shift+tab":     "shift+tab",
+		"ctrl+shift+up": "ctrl+shift+up",
+		"space":         " ",
+		"PageDown":      "pgdown",
+	} {
+		if got, err := parse(in); err != nil || got != want {
+			t.Errorf("parse(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"shift+1", "ctrl+shift+m", "shift+enter", "meta+x"} {
+		if got, err := parse(in); err == nil {
+			t.Errorf("parse(%q) = %q, want an error", in, got)
+		}
+	}
+}
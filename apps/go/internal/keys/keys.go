@@ -0,0 +1,324 @@
+// Package keys holds the reader's key bindings: the defaults, vim and
+// emacs presets, the practitioner's overrides, and the help built from
+// them.
+package keys
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Map is every rebindable action. Text entry (the journal editor,
+// search, the passphrase) keeps its own keys.
+type Map struct {
+	// Everywhere
+	Quit  key.Binding
+	Menu  key.Binding
+	Theme key.Binding
+	Help  key.Binding
+
+	// Lists: the menu and the journal
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+
+	// The invocation
+	Advance    key.Binding
+	Back       key.Binding
+	ScrollUp   key.Binding
+	ScrollDown key.Binding
+	PageUp     key.Binding
+	PageDown   key.Binding
+	HUD        key.Binding
+	Notes      key.Binding // The section's footnotes
+
+	// Highlights, made while reading and removed from their list
+	Mark        key.Binding
+	MarkSection key.Binding
+
+	// The journal, whose entries it searches; elsewhere it searches
+	// the invocation
+	Search key.Binding
+	Write  key.Binding
+	Export key.Binding
+}
+
+// action names a binding for keys.toml, with its help text.
+type action struct {
+	name, desc string
+	field      func(*Map) *key.Binding
+}
+
+var actions = []action{
+	{"quit", "quit", func(m *Map) *key.Binding { return &m.Quit }},
+	{"menu", "menu", func(m *Map) *key.Binding { return &m.Menu }},
+	{"theme", "theme", func(m *Map) *key.Binding { return &m.Theme }},
+	{"help", "help", func(m *Map) *key.Binding { return &m.Help }},
+	{"up", "up", func(m *Map) *key.Binding { return &m.Up }},
+	{"down", "down", func(m *Map) *key.Binding { return &m.Down }},
+	{"select", "open", func(m *Map) *key.Binding { return &m.Select }},
+	{"advance", "next", func(m *Map) *key.Binding { return &m.Advance }},
+	{"back", "back", func(m *Map) *key.Binding { return &m.Back }},
+	{"scroll_up", "scroll up", func(m *Map) *key.Binding { return &m.ScrollUp }},
+	{"scroll_down", "scroll down", func(m *Map) *key.Binding { return &m.ScrollDown }},
+	{"page_up", "page up", func(m *Map) *key.Binding { return &m.PageUp }},
+	{"page_down", "page down", func(m *Map) *key.Binding { return &m.PageDown }},
+	{"hud", "status bar", func(m *Map) *key.Binding { return &m.HUD }},
+	{"notes", "notes", func(m *Map) *key.Binding { return &m.Notes }},
+	{"mark", "highlight", func(m *Map) *key.Binding { return &m.Mark }},
+	{"mark_section", "highlight section", func(m *Map) *key.Binding { return &m.MarkSection }},
+	{"search", "search", func(m *Map) *key.Binding { return &m.Search }},
+	{"write", "new entry", func(m *Map) *key.Binding { return &m.Write }},
+	{"export", "export", func(m *Map) *key.Binding { return &m.Export }},
+}
+
+// build makes a Map from each action's keys, with help text to match.
+func build(bound map[string][]string) Map {
+	var m Map
+	for _, a := range actions {
+		*a.field(&m) = bind(bound[a.name], a.desc)
+	}
+	return m
+}
+
+// bind makes a binding whose help shows its first two keys.
+func bind(keys []string, desc string) key.Binding {
+	shown := make([]string, 0, 2)
+	for _, k := range keys {
+		if len(shown) == 2 {
+			break
+		}
+		shown = append(shown, display(k))
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(shown, "/"), desc))
+}
+
+// display is how help writes a key.
+func display(k string) string {
+	switch k {
+	case " ":
+		return "space"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	}
+	return k
+}
+
+// Default is the reader's own layout.
+func Default() Map {
+	return build(map[string][]string{
+		"quit":         {"q", "ctrl+c"},
+		"menu":         {"esc"},
+		"theme":        {"t"},
+		"help":         {"?"},
+		"up":           {"up", "k"},
+		"down":         {"down", "j"},
+		"select":       {"enter", " ", "right", "l"},
+		"advance":      {" ", "right"},
+		"back":         {"left", "backspace"},
+		"scroll_up":    {"up", "k"},
+		"scroll_down":  {"down", "j"},
+		"page_up":      {"pgup"},
+		"page_down":    {"pgdown"},
+		"hud":          {"i"},
+		"notes":        {"f"},
+		"mark":         {"m"},
+		"mark_section": {"M"},
+		"search":       {"/"},
+		"write":        {"n"},
+		"export":       {"e"},
+	})
+}
+
+// Vim moves with hjkl: l and h turn the pages, ctrl+d/u scroll.
+func Vim() Map {
+	return build(map[string][]string{
+		"quit":         {"q", "ctrl+c"},
+		"menu":         {"esc"},
+		"theme":        {"t"},
+		"help":         {"?"},
+		"up":           {"k", "up"},
+		"down":         {"j", "down"},
+		"select":       {"l", "enter", "right"},
+		"advance":      {"l", " ", "right"},
+		"back":         {"h", "backspace", "left"},
+		"scroll_up":    {"k", "up"},
+		"scroll_down":  {"j", "down"},
+		"page_up":      {"ctrl+u", "ctrl+b", "pgup"},
+		"page_down":    {"ctrl+d", "ctrl+f", "pgdown"},
+		"hud":          {"i"},
+		"notes":        {"f"},
+		"mark":         {"m"},
+		"mark_section": {"M"},
+		"search":       {"/"},
+		"write":        {"o", "n"},
+		"export":       {"e"},
+	})
+}
+
+// Emacs moves with ctrl+n/p and turns pages with ctrl+f/b.
+func Emacs() Map {
+	return build(map[string][]string{
+		"quit":         {"ctrl+c", "q"},
+		"menu":         {"ctrl+g", "esc"},
+		"theme":        {"t"},
+		"help":         {"ctrl+h", "?"},
+		"up":           {"ctrl+p", "up"},
+		"down":         {"ctrl+n", "down"},
+		"select":       {"enter"},
+		"advance":      {"ctrl+f", " ", "right"},
+		"back":         {"ctrl+b", "left"},
+		"scroll_up":    {"ctrl+p", "up"},
+		"scroll_down":  {"ctrl+n", "down"},
+		"page_up":      {"alt+v", "pgup"},
+		"page_down":    {"ctrl+v", "pgdown"},
+		"hud":          {"i"},
+		"notes":        {"f"},
+		"mark":         {"m"},
+		"mark_section": {"M"},
+		"search":       {"ctrl+s", "/"},
+		"write":        {"n"},
+		"export":       {"e"},
+	})
+}
+
+// Presets by name, for keys.toml.
+var presets = map[string]func() Map{
+	"default": Default,
+	"vim":     Vim,
+	"emacs":   Emacs,
+}
+
+// Or returns m, or the defaults when m was never set.
+func (m Map) Or() Map {
+	if len(m.Quit.Keys()) == 0 {
+		return Default()
+	}
+	return m
+}
+
+// View is the bindings that apply in one view, as help shows them.
+type View struct {
+	Short []key.Binding
+	Full  [][]key.Binding
+}
+
+func (v View) ShortHelp() []key.Binding  { return v.Short }
+func (v View) FullHelp() [][]key.Binding { return v.Full }
+
+// MenuView is the menu's bindings.
+func (m Map) MenuView() View {
+	return View{
+		Short: []key.Binding{m.Select, m.Search, m.Theme, m.Help, m.Quit},
+		Full:  [][]key.Binding{{m.Up, m.Down, m.Select, m.Search}, {m.Theme, m.Help, m.Quit}},
+	}
+}
+
+// ReaderView is the invocation's bindings.
+func (m Map) ReaderView() View {
+	return View{
+		Short: []key.Binding{m.Advance, m.Back, m.Help},
+		Full: [][]key.Binding{
+			{m.Advance, m.Back, m.HUD, m.Search},
+			{m.Mark, m.MarkSection, m.Notes},
+			{m.ScrollUp, m.ScrollDown, m.PageUp, m.PageDown},
+			{m.Menu, m.Theme, m.Help, m.Quit},
+		},
+	}
+}
+
+// JournalView is the journal list's bindings.
+func (m Map) JournalView() View {
+	return View{
+		Short: []key.Binding{m.Select, m.Search, m.Write, m.Export, m.Menu},
+		Full: [][]key.Binding{
+			{m.Up, m.Down, m.Select, m.Back},
+			{m.Search, m.Write, m.Export},
+			{m.Menu, m.Theme, m.Help, m.Quit},
+		},
+	}
+}
+
+// SearchView is the search results' bindings, once the query is left.
+func (m Map) SearchView() View {
+	return View{
+		Short: []key.Binding{m.Select, m.Search, m.Menu},
+		Full: [][]key.Binding{
+			{m.Up, m.Down, m.Select, m.Search},
+			{m.Menu, m.Theme, m.Help, m.Quit},
+		},
+	}
+}
+
+// ReferencesView is the notes and reading list's bindings.
+func (m Map) ReferencesView() View {
+	return View{
+		Short: []key.Binding{m.ScrollDown, m.PageDown, m.Menu},
+		Full: [][]key.Binding{
+			{m.ScrollUp, m.ScrollDown, m.PageUp, m.PageDown},
+			{m.Menu, m.Theme, m.Help, m.Quit},
+		},
+	}
+}
+
+// views is every screen's bindings by the screen's name, for checking
+// that no key means two things on one of them.
+func (m Map) views() map[string]View {
+	return map[string]View{
+		"menu":       m.MenuView(),
+		"reader":     m.ReaderView(),
+		"journal":    m.JournalView(),
+		"search":     m.SearchView(),
+		"references": m.ReferencesView(),
+		"highlights": m.HighlightsView(),
+	}
+}
+
+// HighlightsView is the highlights list's bindings. The highlight keys
+// remove one there.
+func (m Map) HighlightsView() View {
+	remove := key.NewBinding(key.WithKeys(m.Mark.Keys()...), key.WithHelp(m.Mark.Help().Key, "remove"))
+	return View{
+		Short: []key.Binding{m.Select, remove, m.Export, m.Menu},
+		Full: [][]key.Binding{
+			{m.Up, m.Down, m.Select},
+			{remove, m.Export},
+			{m.Menu, m.Theme, m.Help, m.Quit},
+		},
+	}
+}
+
+// NewHelp makes a help bubble in th's colours.
+func NewHelp(r *lipgloss.Renderer, th theme.Theme) help.Model {
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	quiet := theme.Degraded(r)
+	keyStyle := r.NewStyle().Foreground(th.Accent)
+	descStyle := r.NewStyle().Foreground(th.Faded).Faint(quiet)
+	sepStyle := r.NewStyle().Foreground(th.Dim).Faint(quiet)
+
+	h := help.New()
+	h.ShortSeparator = " · "
+	h.Styles = help.Styles{
+		Ellipsis:       sepStyle,
+		ShortKey:       keyStyle,
+		ShortDesc:      descStyle,
+		ShortSeparator: sepStyle,
+		FullKey:        keyStyle,
+		FullDesc:       descStyle,
+		FullSeparator:  sepStyle,
+	}
+	return h
+}
@@ -0,0 +1,127 @@
+// Package layout holds the breakpoints the reader's views share, so a
+// phone terminal (the practitioner's vajra, often Termux over SSH) gets
+// the same compact treatment everywhere.
+package layout
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	MinWidth    = 24 // Below this the views give way to a "too small" screen
+	MinHeight   = 8
+	NarrowWidth = 60 // Below this, margins shrink and headers compact
+	ShortHeight = 20 // Below this, vertical spacing tightens
+)
+
+// TooSmall reports whether a w×h terminal is below the hard minimum.
+func TooSmall(w, h int) bool {
+	return w < MinWidth || h < MinHeight
+}
+
+// Narrow reports whether w columns call for the compact layout.
+func Narrow(w int) bool {
+	return w < NarrowWidth
+}
+
+// Short reports whether h rows call for tighter vertical spacing.
+func Short(h int) bool {
+	return h < ShortHeight
+}
+
+// Margin is the blank columns kept each side of body text.
+func Margin(w int) int {
+	switch {
+	case w < 40:
+		return 1
+	case Narrow(w):
+		return 3
+	}
+	return 6
+}
+
+// TextWidth is the measure body text wraps to in a w-column page.
+func TextWidth(w int) int {
+	return max(1, w-2*Margin(w))
+}
+
+// TooSmallView asks for a bigger terminal, centred in w×h.
+func TooSmallView(w, h int, style lipgloss.Style) string {
+	msg := fmt.Sprintf("Terminal too small\n%d×%d, need %d×%d", w, h, MinWidth, MinHeight)
+	if w < 20 {
+		msg = fmt.Sprintf("%d×%d\nneed %d×%d", w, h, MinWidth, MinHeight)
+	}
+	return lipgloss.Place(w, h, lipgloss.Center, lipgloss.Center, style.Render(msg))
+}
+
+// Wrap breaks text into lines of at most width cells. A word that
+// doesn't fit is broken after a hyphen it already has, when that lets
+// its front fit; one wider than the whole line is hyphenated where it
+// must. **Emphasis** markers take no room.
+func Wrap(text string, width int) []string {
+	width = max(1, width)
+	var lines []string
+	cur := ""
+	for _, word := range strings.Fields(text) {
+		for word != "" {
+			sep := ""
+			if cur != "" {
+				sep = " "
+			}
+			room := width - visible(cur) - len(sep)
+			if visible(word) <= room {
+				cur += sep + word
+				break
+			}
+			if head, tail, ok := splitHyphen(word, room); ok {
+				lines = append(lines, cur+sep+head)
+				cur, word = "", tail
+				continue
+			}
+			if cur != "" {
+				lines = append(lines, cur)
+				cur = ""
+				continue
+			}
+			head, tail := splitForce(word, width)
+			lines = append(lines, head)
+			word = tail
+		}
+	}
+	if cur != "" || len(lines) == 0 {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// visible is the cells s takes once emphasis markers are dropped.
+func visible(s string) int {
+	return lipgloss.Width(strings.ReplaceAll(s, "**", ""))
+}
+
+// splitHyphen breaks word after its last hyphen that leaves a front
+// fitting in room.
+func splitHyphen(word string, room int) (head, tail string, ok bool) {
+	for i := len(word) - 2; i > 0; i-- {
+		if word[i] == '-' && visible(word[:i+1]) <= room {
+			return word[:i+1], word[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// splitForce hyphenates a word too wide for any line.
+func splitForce(word string, width int) (head, tail string) {
+	runes := []rune(word)
+	n := 1
+	for n < len(runes)-1 && visible(string(runes[:n+1]))+1 <= width {
+		n++
+	}
+	if width == 1 {
+		return string(runes[:1]), string(runes[1:])
+	}
+	return string(runes[:n]) + "-", string(runes[n:])
+}
@@ -0,0 +1,112 @@
+// Package manifesto is the whole document the invocation opens, read
+// as a page: the community, the two devices, the rituals and the
+// philosophy notes after it.
+package manifesto
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gorkolas/cybertantra/assets"
+	"github.com/gorkolas/cybertantra/internal/invocation"
+)
+
+// Line is one line of the document, with the headings it sits under.
+type Line struct {
+	N     int      // Line number in the document, from 0
+	Text  string   // As written
+	Level int      // The heading's level when the line is one, else 0
+	Under []string // Titles of the headings over it, outermost first
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+)$`)
+	noteDefPattern = regexp.MustCompile(`^\[\^[^\]]+\]:`)
+)
+
+// Lines is the document a line at a time, read once.
+var Lines = sync.OnceValue(func() []Line {
+	return Parse(assets.Manifesto)
+})
+
+// Parse splits markdown into lines, each knowing its headings. Inside
+// a fenced code block a # starts no heading.
+func Parse(md string) []Line {
+	var (
+		lines  []Line
+		trail  []string
+		levels []int
+		fenced bool
+	)
+	for n, text := range strings.Split(md, "\n") {
+		line := Line{N: n, Text: text}
+		if strings.HasPrefix(strings.TrimSpace(text), "```") {
+			fenced = !fenced
+		}
+		if m := headingPattern.FindStringSubmatch(text); m != nil && !fenced {
+			level := len(m[1])
+			for len(levels) > 0 && levels[len(levels)-1] >= level {
+				trail, levels = trail[:len(trail)-1], levels[:len(levels)-1]
+			}
+			line.Level = level
+			line.Under = trail
+			trail = append(trail[:len(trail):len(trail)], Plain(m[2]))
+			levels = append(levels, level)
+		} else {
+			line.Under = trail
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Title is a heading's text, or the line as it reads.
+func (l Line) Title() string {
+	if m := headingPattern.FindStringSubmatch(l.Text); m != nil && l.Level > 0 {
+		return Plain(m[2])
+	}
+	return Plain(l.Text)
+}
+
+// Invocation reports whether the line belongs to the invocation, the
+// first part, which the reader itself reveals.
+func (l Line) Invocation() bool {
+	top := l.Under
+	if l.Level == 2 {
+		top = []string{l.Title()}
+	}
+	return len(top) > 0 && strings.HasPrefix(top[0], "Part I:")
+}
+
+// Readable reports whether the line is worth finding: not blank, a rule,
+// a code fence, a table's divider or a note's definition, which the
+// invocation's notes already hold.
+func (l Line) Readable() bool {
+	t := strings.TrimSpace(l.Text)
+	switch {
+	case t == "", t == "---", strings.HasPrefix(t, "```"):
+		return false
+	case strings.HasPrefix(t, "|") && strings.Trim(t, "|-: ") == "":
+		return false
+	case noteDefPattern.MatchString(t):
+		return false
+	}
+	return true
+}
+
+// Inline is markdown text as it reads, links aside: emphasis, note
+// references and checkboxes gone.
+func Inline(s string) string {
+	s = invocation.StripNoteRefs(s)
+	s = strings.NewReplacer("**", "", "- [ ] ", "- ", "- [x] ", "- ").Replace(s)
+	s = emphasisPattern.ReplaceAllString(s, "$1$2")
+	return strings.TrimSpace(s)
+}
+
+// Plain is Inline with links down to their labels.
+func Plain(s string) string {
+	return Inline(invocation.ReplaceLinks(s, func(label, url string) string { return label }))
+}
+
+var emphasisPattern = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*`)
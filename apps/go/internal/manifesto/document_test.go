@@ -0,0 +1,38 @@
+package manifesto
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	lines := Parse("## Part I: **A**\n### One\ntext\n```\n# not a heading\n```\n## Part II: B\n#### Deep\n[^1]: a note\n")
+	if !lines[2].Invocation() || lines[6].Invocation() {
+		t.Error("Invocation should hold to Part I")
+	}
+	if got := lines[2].Under; !slices.Equal(got, []string{"Part I: A", "One"}) {
+		t.Errorf("Under = %q", got)
+	}
+	if lines[4].Level != 0 {
+		t.Error("a # inside a fence read as a heading")
+	}
+	if got := lines[7].Under; !slices.Equal(got, []string{"Part II: B"}) {
+		t.Errorf("Under after a new part = %q", got)
+	}
+	if lines[8].Readable() || lines[3].Readable() {
+		t.Error("a note's definition and a fence should not be readable")
+	}
+}
+
+func TestPlain(t *testing.T) {
+	for in, want := range map[string]string{
+		"**Money** is *prana*.":             "Money is prana.",
+		"a [label](https://x) b[^1]":        "a label b",
+		"- [ ] **tool** — for *(TODAY)*":    "- tool — for (TODAY)",
+		"`2*3` stays and *[TODO: find it]*": "`2*3` stays and [TODO: find it]",
+	} {
+		if got := Plain(in); got != want {
+			t.Errorf("Plain(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package manifesto
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Model is the manifesto pane: everything after the invocation, from
+// the reading list to the open questions, scrolled as one page.
+type Model struct {
+	lines    []string // The page as last laid out
+	from     []int    // The document line each row comes from
+	at       int      // The document line at the top, kept across layouts
+	offset   int
+	links    bool // Links are clickable
+	keys     keys.Map
+	help     help.Model
+	theme    theme.Theme
+	width    int
+	height   int
+	ready    bool
+	renderer *lipgloss.Renderer
+}
+
+func New(r *lipgloss.Renderer, th theme.Theme, km keys.Map, links bool) Model {
+	return NewAt(r, th, km, links, 0)
+}
+
+// NewAt opens the pane with document line n at the top, or the nearest
+// shown line after it.
+func NewAt(r *lipgloss.Renderer, th theme.Theme, km keys.Map, links bool, n int) Model {
+	return Model{
+		at:       n,
+		links:    links,
+		keys:     km.Or(),
+		help:     keys.NewHelp(r, th),
+		theme:    th,
+		renderer: r,
+	}
+}
+
+// Capturing reports false: the pane takes no text.
+func (m Model) Capturing() bool {
+	return false
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case theme.ChangedMsg:
+		m.theme = msg.Theme
+		m.help = keys.NewHelp(m.renderer, m.theme)
+		m.relayout()
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.relayout()
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scroll(-3)
+		case tea.MouseButtonWheelDown:
+			m.scroll(3)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.ScrollUp):
+			m.scroll(-1)
+		case key.Matches(msg, m.keys.ScrollDown):
+			m.scroll(1)
+		case key.Matches(msg, m.keys.PageUp):
+			m.scroll(-max(1, m.pageHeight()-2))
+		case key.Matches(msg, m.keys.PageDown):
+			m.scroll(max(1, m.pageHeight()-2))
+		}
+	}
+	return m, nil
+}
+
+// relayout lays the page out again, keeping the same document line at
+// the top.
+func (m *Model) relayout() {
+	m.lines, m.from = m.layout()
+	m.offset = len(m.from)
+	for i, n := range m.from {
+		if n >= m.at {
+			m.offset = i
+			break
+		}
+	}
+	m.scroll(0)
+}
+
+// scroll moves the page by delta lines, stopping at either end.
+func (m *Model) scroll(delta int) {
+	m.offset = max(0, min(m.offset+delta, len(m.lines)-m.pageHeight()))
+	if m.offset < len(m.from) {
+		m.at = m.from[m.offset]
+	}
+}
+
+func (m Model) contentWidth() int {
+	_, x := m.padding()
+	return max(10, min(m.width-2*x, 72))
+}
+
+func (m Model) pageHeight() int {
+	y, _ := m.padding()
+	return max(3, m.height-4-2*y)
+}
+
+// padding is the space around the pane, dropped to a column on a
+// narrow or short terminal.
+func (m Model) padding() (y, x int) {
+	if layout.Narrow(m.width) || layout.Short(m.height) {
+		return 0, 1
+	}
+	return 1, 2
+}
+
+// layout renders the document after the invocation at the pane's
+// width, a line to a row, and the document line each row is from.
+// Rules become gaps, runs of gaps one, and notes' definitions are left
+// to the references.
+func (m Model) layout() ([]string, []int) {
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	th := m.theme
+	quiet := theme.Degraded(r)
+	styles := map[int]lipgloss.Style{
+		2: r.NewStyle().Foreground(th.Title).Bold(true),
+		3: r.NewStyle().Foreground(th.Accent).Bold(true),
+		4: r.NewStyle().Foreground(th.Bright).Bold(true),
+	}
+	textStyle := r.NewStyle().Foreground(th.Text)
+	codeStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+
+	w := m.contentWidth()
+	var out []string
+	var from []int
+	add := func(n int, style lipgloss.Style, indent string, lines []string) {
+		for _, l := range lines {
+			out = append(out, indent+style.Render(l))
+			from = append(from, n)
+		}
+	}
+	gap := func(n int) {
+		if len(out) > 0 && out[len(out)-1] != "" {
+			out = append(out, "")
+			from = append(from, n)
+		}
+	}
+
+	fenced := false
+	for _, l := range Lines() {
+		t := strings.TrimSpace(l.Text)
+		if strings.HasPrefix(t, "```") {
+			fenced = !fenced
+			continue
+		}
+		switch {
+		case l.Invocation():
+		case fenced:
+			add(l.N, codeStyle, "  ", layout.Wrap(l.Text, w-2))
+		case l.Level > 0:
+			gap(l.N)
+			style, ok := styles[l.Level]
+			if !ok {
+				style = styles[4]
+			}
+			add(l.N, style, "", layout.Wrap(l.Title(), w))
+		case t == "" || t == "---":
+			gap(l.N)
+		case l.Readable():
+			add(l.N, textStyle, "", invocation.WrapLinks(Inline(l.Text), w, m.links))
+		}
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out, from = out[:len(out)-1], from[:len(from)-1]
+	}
+	return out, from
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	titleStyle := r.NewStyle().Foreground(m.theme.Accent).Bold(true)
+
+	help := m.help
+	help.Width = m.contentWidth()
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("॥ MANIFESTO ॥"))
+	b.WriteString("\n\n")
+	h := m.pageHeight()
+	shown := m.lines[m.offset:min(m.offset+h, len(m.lines))]
+	b.WriteString(strings.Join(shown, "\n"))
+	b.WriteString(strings.Repeat("\n", h-len(shown)+1))
+	b.WriteString(help.ShortHelpView(m.keys.ReferencesView().ShortHelp()))
+
+	return r.NewStyle().Padding(m.padding()).Render(b.String())
+}
@@ -0,0 +1,145 @@
+// Package mantra draws standalone lines from the invocation: every key
+// line, and every line set in bold, for prompts, MOTDs and fortune.
+package mantra
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+)
+
+// Mantra is one line that stands on its own.
+type Mantra struct {
+	Text    string
+	Section string // Title of the section it comes from
+}
+
+// All gathers the mantras from sections, in reading order: each key
+// line, then the section's bold sentences. A sentence set in bold
+// across several lines is one mantra, and bold that starts mid-sentence
+// takes the plain words before it from the same paragraph.
+func All(sections []invocation.Section) []Mantra {
+	var out []Mantra
+	for _, s := range sections {
+		out = append(out, Mantra{Text: s.KeyLine, Section: s.Title})
+		lines := make([]string, len(s.Lines))
+		for i, line := range s.Lines {
+			lines[i] = strings.TrimSpace(invocation.StripNoteRefs(line))
+		}
+		for i := 0; i < len(lines); i++ {
+			if !isBold(lines[i]) {
+				continue
+			}
+			start := i
+			for !endsSentence(lines[i]) && i+1 < len(lines) && isBold(lines[i+1]) {
+				i++
+			}
+			for start > 0 && startsLower(lines[start]) && lines[start-1] != "" && !endsSentence(lines[start-1]) {
+				start--
+			}
+			var words []string
+			for _, line := range lines[start : i+1] {
+				words = append(words, strings.Trim(line, "*"))
+			}
+			text := strings.Join(words, " ")
+			if startsLower(text) || !endsSentence(text) {
+				continue
+			}
+			out = append(out, Mantra{Text: text, Section: s.Title})
+		}
+	}
+	return out
+}
+
+func isBold(line string) bool {
+	return strings.HasPrefix(line, "**") && strings.HasSuffix(line, "**") && len(line) >= 5
+}
+
+func startsLower(line string) bool {
+	r, _ := utf8.DecodeRuneInString(strings.TrimLeft(line, "*"))
+	return unicode.IsLower(r)
+}
+
+func endsSentence(line string) bool {
+	line = strings.TrimRight(line, "*")
+	return line != "" && strings.ContainsRune(".!?", rune(line[len(line)-1]))
+}
+
+// ForDay picks the mantra for t's calendar day. Consecutive days walk
+// through the list in order, so none repeats until all have been seen.
+func ForDay(list []Mantra, t time.Time) Mantra {
+	if len(list) == 0 {
+		return Mantra{}
+	}
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	return list[int(day%int64(len(list)))]
+}
+
+// Random picks any mantra.
+func Random(list []Mantra) Mantra {
+	if len(list) == 0 {
+		return Mantra{}
+	}
+	return list[rand.IntN(len(list))]
+}
+
+// fortuneEntry is how a mantra reads when fortune prints it.
+func fortuneEntry(m Mantra) string {
+	return m.Text + "\n\t\t-- Cybertantra, " + m.Section + "\n"
+}
+
+// WriteFortune writes list as a fortune(6) source file: entries
+// separated by lines holding a single %.
+func WriteFortune(w io.Writer, list []Mantra) error {
+	var b strings.Builder
+	for _, m := range list {
+		b.WriteString(fortuneEntry(m) + "%\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// strfile header values; see strfile(8).
+const (
+	strfileVersion = 2
+	strfileDelim   = '%'
+)
+
+// WriteStrfile writes the index strfile(8) would build for the file
+// WriteFortune writes, so fortune can read it without strfile installed.
+func WriteStrfile(w io.Writer, list []Mantra) error {
+	offsets := make([]uint32, 0, len(list)+1)
+	var longest, shortest uint32
+	var pos uint32
+	for i, m := range list {
+		offsets = append(offsets, pos)
+		n := uint32(len(fortuneEntry(m)))
+		if n > longest {
+			longest = n
+		}
+		if i == 0 || n < shortest {
+			shortest = n
+		}
+		pos += n + 2 // The "%\n" after it
+	}
+	offsets = append(offsets, pos)
+
+	var b bytes.Buffer
+	header := []uint32{strfileVersion, uint32(len(list)), longest, shortest, 0}
+	for _, v := range header {
+		binary.Write(&b, binary.BigEndian, v)
+	}
+	b.Write([]byte{strfileDelim, 0, 0, 0})
+	for _, off := range offsets {
+		binary.Write(&b, binary.BigEndian, off)
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
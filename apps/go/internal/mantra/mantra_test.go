@@ -0,0 +1,50 @@
+package mantra
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+)
+
+func TestAllAreSentences(t *testing.T) {
+	list := All(invocation.Sections())
+	if len(list) == 0 {
+		t.Fatal("no mantras")
+	}
+	for _, m := range list {
+		if r, _ := utf8.DecodeRuneInString(m.Text); unicode.IsLower(r) {
+			t.Errorf("%q starts mid-sentence", m.Text)
+		}
+		if !strings.HasSuffix(m.Text, ".") && !strings.HasSuffix(m.Text, "!") && !strings.HasSuffix(m.Text, "?") {
+			t.Errorf("%q ends mid-sentence", m.Text)
+		}
+	}
+}
+
+func TestAllJoinsBoldLines(t *testing.T) {
+	sections := []invocation.Section{{
+		Title:   "Test",
+		KeyLine: "Key.",
+		Lines: []string{
+			"**One sentence set**",
+			"**across two lines.**",
+			"**Another after it.**",
+			"",
+			"Plain words lead",
+			"**into bold.**",
+			"",
+			"**A fragment**",
+		},
+	}}
+	var got []string
+	for _, m := range All(sections) {
+		got = append(got, m.Text)
+	}
+	want := "Key. | One sentence set across two lines. | Another after it. | Plain words lead into bold."
+	if strings.Join(got, " | ") != want {
+		t.Errorf("All = %q, want %q", strings.Join(got, " | "), want)
+	}
+}
@@ -0,0 +1,207 @@
+// Package references is the invocation's notes, gathered as endnotes,
+// and the reading recommended alongside it.
+package references
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Model is the references pane: every note by its number, then the
+// recommended reading, scrolled as one page.
+type Model struct {
+	lines    []string // The page as last laid out
+	offset   int
+	links    bool // Links are clickable
+	keys     keys.Map
+	help     help.Model
+	theme    theme.Theme
+	width    int
+	height   int
+	ready    bool
+	renderer *lipgloss.Renderer
+}
+
+func New(r *lipgloss.Renderer, th theme.Theme, km keys.Map, links bool) Model {
+	return Model{
+		links:    links,
+		keys:     km.Or(),
+		help:     keys.NewHelp(r, th),
+		theme:    th,
+		renderer: r,
+	}
+}
+
+// Capturing reports false: the pane takes no text.
+func (m Model) Capturing() bool {
+	return false
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case theme.ChangedMsg:
+		m.theme = msg.Theme
+		m.help = keys.NewHelp(m.renderer, m.theme)
+		m.lines = m.layout()
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.lines = m.layout()
+		m.scroll(0)
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.scroll(-3)
+		case tea.MouseButtonWheelDown:
+			m.scroll(3)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.ScrollUp):
+			m.scroll(-1)
+		case key.Matches(msg, m.keys.ScrollDown):
+			m.scroll(1)
+		case key.Matches(msg, m.keys.PageUp):
+			m.scroll(-max(1, m.pageHeight()-2))
+		case key.Matches(msg, m.keys.PageDown):
+			m.scroll(max(1, m.pageHeight()-2))
+		}
+	}
+	return m, nil
+}
+
+// scroll moves the page by delta lines, stopping at either end.
+func (m *Model) scroll(delta int) {
+	m.offset = max(0, min(m.offset+delta, len(m.lines)-m.pageHeight()))
+}
+
+func (m Model) contentWidth() int {
+	_, x := m.padding()
+	return max(10, min(m.width-2*x, 72))
+}
+
+func (m Model) pageHeight() int {
+	y, _ := m.padding()
+	return max(3, m.height-4-2*y)
+}
+
+// padding is the space around the pane, dropped to a column on a
+// narrow or short terminal.
+func (m Model) padding() (y, x int) {
+	if layout.Narrow(m.width) || layout.Short(m.height) {
+		return 0, 1
+	}
+	return 1, 2
+}
+
+// layout renders the notes and the reading list at the pane's width,
+// a line to a row.
+func (m Model) layout() []string {
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	th := m.theme
+	quiet := theme.Degraded(r)
+	headStyle := r.NewStyle().Foreground(th.Title).Bold(true)
+	markStyle := r.NewStyle().Foreground(th.Accent).Bold(true)
+	titleStyle := r.NewStyle().Foreground(th.Bright).Bold(true)
+	textStyle := r.NewStyle().Foreground(th.Text)
+	placeStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+	aboutStyle := r.NewStyle().Foreground(th.Faded).Faint(quiet).Italic(true)
+
+	w := m.contentWidth()
+	var out []string
+	add := func(style lipgloss.Style, indent string, lines []string) {
+		for _, l := range lines {
+			out = append(out, indent+style.Render(l))
+		}
+	}
+
+	sections := invocation.Sections()
+	if notes := invocation.Endnotes(sections); len(notes) > 0 {
+		out = append(out, headStyle.Render("Notes"), "")
+		for _, n := range notes {
+			mark := invocation.Superscript(n.N)
+			indent := strings.Repeat(" ", lipgloss.Width(mark)+1)
+			place := fmt.Sprintf("%d. %s", n.Section+1, sections[n.Section].Title)
+			out = append(out, markStyle.Render(mark)+" "+placeStyle.Render(place))
+			add(textStyle, indent, invocation.WrapLinks(n.Text, w-len(indent), m.links))
+			out = append(out, "")
+		}
+		out = append(out, "")
+	}
+
+	intro, refs := invocation.Reading()
+	out = append(out, headStyle.Render("Recommended Reading"), "")
+	if intro != "" {
+		add(textStyle, "", layout.Wrap(intro, w))
+		out = append(out, "")
+	}
+	for _, ref := range refs {
+		head := ref.Title
+		if ref.Author != "" {
+			head += " — " + ref.Author
+		}
+		add(titleStyle, "", layout.Wrap(head, w))
+		if ref.About != "" {
+			add(aboutStyle, "  ", layout.Wrap(ref.About, w-2))
+		}
+		for _, l := range ref.Links {
+			add(textStyle, "  ", invocation.WrapLinks("→ ["+l.Label+"]("+l.URL+")", w-2, m.links))
+		}
+		out = append(out, "")
+	}
+	return out[:len(out)-1]
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	titleStyle := r.NewStyle().Foreground(m.theme.Accent).Bold(true)
+
+	help := m.help
+	help.Width = m.contentWidth()
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("॥ REFERENCES ॥"))
+	b.WriteString("\n\n")
+	h := m.pageHeight()
+	shown := m.lines[m.offset:min(m.offset+h, len(m.lines))]
+	b.WriteString(strings.Join(shown, "\n"))
+	b.WriteString(strings.Repeat("\n", h-len(shown)+1))
+	b.WriteString(help.ShortHelpView(m.keys.ReferencesView().ShortHelp()))
+
+	return r.NewStyle().Padding(m.padding()).Render(b.String())
+}
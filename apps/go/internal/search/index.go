@@ -0,0 +1,273 @@
+// Package search finds where words appear in the manifesto: the
+// invocation's part title, section titles and key lines, body lines,
+// reflection prompts and notes, then the headings and lines of every
+// part after it, through an inverted index built once in memory.
+package search
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/manifesto"
+)
+
+// Kind is the sort of text a document is.
+type Kind int
+
+const (
+	Part    Kind = iota // The part's title
+	Title               // A section's title
+	KeyLine             // A section's key line
+	Line                // A body line
+	Prompt              // The reflection question after a section
+	Note                // A note a section cites
+	Heading             // A heading after the invocation, like a ritual's
+	Page                // A line after the invocation
+)
+
+// weight ranks a hit by where it is: a title says more than a note.
+var weight = [...]float64{
+	Part:    3,
+	Title:   3,
+	KeyLine: 2,
+	Line:    1,
+	Prompt:  1,
+	Note:    0.8,
+	Heading: 2,
+	Page:    1,
+}
+
+// Doc is one indexed piece of text.
+type Doc struct {
+	Kind    Kind
+	Section int    // Index into invocation.Sections()
+	Line    int    // Body line it is at, or that cites it; -1 is the section's head. For a heading or page, the manifesto's line
+	Text    string // As read: bold markers and note references gone
+	Where   string // For a heading or page, the headings over it
+}
+
+// Result is a document that matched, with where.
+type Result struct {
+	Doc
+	Score   float64
+	Matches [][2]int // Byte ranges of Text that matched, in order
+}
+
+// token is one word of a document.
+type token struct {
+	term       string // Lower case
+	start, end int    // Byte range in the document's text
+}
+
+// posting is a term's occurrences in one document.
+type posting struct {
+	doc, count int
+}
+
+// Index maps each term to the documents holding it.
+type Index struct {
+	docs     []Doc
+	tokens   [][]token
+	postings map[string][]posting
+	terms    []string // Sorted, for prefix lookups
+}
+
+// NewIndex indexes sections, then every heading and line of doc
+// outside the invocation.
+func NewIndex(sections []invocation.Section, doc []manifesto.Line) *Index {
+	ix := &Index{postings: make(map[string][]posting)}
+	ix.add(Doc{Kind: Part, Line: -1, Text: invocation.Title})
+	for i, s := range sections {
+		ix.add(Doc{Kind: Title, Section: i, Line: -1, Text: s.Title})
+		ix.add(Doc{Kind: KeyLine, Section: i, Line: -1, Text: s.KeyLine})
+		cites := map[string]int{}
+		for j, line := range s.Lines {
+			invocation.ReplaceNoteRefs(line, func(id string) string {
+				if _, ok := cites[id]; !ok {
+					cites[id] = j
+				}
+				return ""
+			})
+			if text := readable(line); text != "" {
+				ix.add(Doc{Kind: Line, Section: i, Line: j, Text: text})
+			}
+		}
+		if s.Prompt != "" {
+			ix.add(Doc{Kind: Prompt, Section: i, Line: len(s.Lines) - 1, Text: s.Prompt})
+		}
+		for _, n := range s.Notes {
+			line, ok := cites[n.ID]
+			if !ok {
+				line = -1
+			}
+			text := invocation.ReplaceLinks(n.Text, func(label, url string) string { return label })
+			ix.add(Doc{Kind: Note, Section: i, Line: line, Text: text})
+		}
+	}
+	for _, l := range doc {
+		if l.Invocation() || !l.Readable() {
+			continue
+		}
+		d := Doc{Kind: Page, Line: l.N, Text: manifesto.Plain(l.Text), Where: strings.Join(l.Under, " › ")}
+		if l.Level > 0 {
+			d.Kind, d.Text = Heading, l.Title()
+		}
+		ix.add(d)
+	}
+	for t := range ix.postings {
+		ix.terms = append(ix.terms, t)
+	}
+	sort.Strings(ix.terms)
+	return ix
+}
+
+// Default is the index of the manifesto, built on first use.
+var Default = sync.OnceValue(func() *Index {
+	return NewIndex(invocation.Sections(), manifesto.Lines())
+})
+
+// readable is a body line as it reads on screen.
+func readable(line string) string {
+	return strings.TrimSpace(strings.ReplaceAll(invocation.StripNoteRefs(line), "**", ""))
+}
+
+func (ix *Index) add(d Doc) {
+	toks := tokenize(d.Text)
+	id := len(ix.docs)
+	ix.docs = append(ix.docs, d)
+	ix.tokens = append(ix.tokens, toks)
+	counts := map[string]int{}
+	for _, t := range toks {
+		counts[t.term]++
+	}
+	for term, n := range counts {
+		ix.postings[term] = append(ix.postings[term], posting{id, n})
+	}
+}
+
+// tokenize splits text into words: runs of letters and digits, folded
+// to lower case. An apostrophe inside a word keeps it whole.
+func tokenize(text string) []token {
+	var toks []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if !word && start >= 0 && (r == '\'' || r == '’') {
+			next, _ := utf8.DecodeRuneInString(text[i+utf8.RuneLen(r):])
+			word = unicode.IsLetter(next)
+		}
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			toks = append(toks, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		toks = append(toks, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return toks
+}
+
+// Search finds the documents holding every word of query, best first.
+// The last word also matches as a prefix, so results follow typing;
+// whole words rank above prefixes, titles above body lines, and the
+// query found as a phrase above its words scattered.
+func (ix *Index) Search(query string) []Result {
+	words := tokenize(query)
+	if len(words) == 0 {
+		return nil
+	}
+
+	// Each word's candidate terms, whole word first
+	scores := map[int]float64{}
+	for i, w := range words {
+		terms := []string{w.term}
+		if i == len(words)-1 {
+			terms = ix.prefixed(w.term)
+		}
+		found := map[int]float64{}
+		for _, term := range terms {
+			exact := 1.0
+			if term != w.term {
+				exact = 0.5
+			}
+			list := ix.postings[term]
+			idf := math.Log(1 + float64(len(ix.docs))/float64(len(list)))
+			for _, p := range list {
+				s := idf * (1 + math.Log(float64(p.count))) * exact
+				found[p.doc] = max(found[p.doc], s)
+			}
+		}
+		if i == 0 {
+			scores = found
+			continue
+		}
+		for doc := range scores {
+			if s, ok := found[doc]; ok {
+				scores[doc] += s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	results := make([]Result, 0, len(scores))
+	for id, s := range scores {
+		d := ix.docs[id]
+		s *= weight[d.Kind]
+		if len(words) > 1 && strings.Contains(strings.ToLower(d.Text), phrase) {
+			s *= 2
+		}
+		results = append(results, Result{Doc: d, Score: s, Matches: ix.matches(id, words)})
+	}
+	sort.Slice(results, func(a, b int) bool {
+		ra, rb := results[a], results[b]
+		if ra.Score != rb.Score {
+			return ra.Score > rb.Score
+		}
+		if ra.Section != rb.Section {
+			return ra.Section < rb.Section
+		}
+		if ra.Line != rb.Line {
+			return ra.Line < rb.Line
+		}
+		return ra.Kind < rb.Kind
+	})
+	return results
+}
+
+// prefixed is every indexed term starting with prefix, itself first
+// when indexed.
+func (ix *Index) prefixed(prefix string) []string {
+	i := sort.SearchStrings(ix.terms, prefix)
+	j := i
+	for j < len(ix.terms) && strings.HasPrefix(ix.terms[j], prefix) {
+		j++
+	}
+	return ix.terms[i:j]
+}
+
+// matches is where document id holds the query's words. A prefix
+// match marks only the prefix.
+func (ix *Index) matches(id int, words []token) [][2]int {
+	var out [][2]int
+	last := words[len(words)-1].term
+	for _, t := range ix.tokens[id] {
+		switch {
+		case slices.ContainsFunc(words, func(w token) bool { return w.term == t.term }):
+			out = append(out, [2]int{t.start, t.end})
+		case strings.HasPrefix(t.term, last):
+			out = append(out, [2]int{t.start, min(t.end, t.start+len(last))})
+		}
+	}
+	return out
+}
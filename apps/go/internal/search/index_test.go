@@ -0,0 +1,43 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorkolas/cybertantra/internal/manifesto"
+)
+
+// TestSearchReachesPastInvocation finds words only the later parts
+// hold, each at the manifesto line that holds it.
+func TestSearchReachesPastInvocation(t *testing.T) {
+	doc := manifesto.Lines()
+	for _, word := range []string{"prana", "Vajra", "kin"} {
+		var pages int
+		for _, r := range Default().Search(word) {
+			if r.Kind != Heading && r.Kind != Page {
+				continue
+			}
+			pages++
+			if text := strings.ToLower(doc[r.Line].Text); !strings.Contains(text, strings.ToLower(word)) {
+				t.Errorf("search %q: line %d reads %q", word, r.Line+1, doc[r.Line].Text)
+			}
+		}
+		if pages == 0 {
+			t.Errorf("search %q found nothing past the invocation", word)
+		}
+	}
+
+	if results := Default().Search("geoffreyhuntley"); len(results) != 1 || results[0].Kind != Note {
+		t.Errorf("search geoffreyhuntley = %+v, want only the note", results)
+	}
+}
+
+func TestSearchRitualHeading(t *testing.T) {
+	results := Default().Search("financial prana")
+	if len(results) == 0 || results[0].Kind != Heading {
+		t.Fatalf("search financial prana = %+v, want the ritual's heading first", results)
+	}
+	if want := "Part IV: THE INITIATION RITUALS › Optional Rituals (When Ready)"; results[0].Place() != want {
+		t.Errorf("Place() = %q, want %q", results[0].Place(), want)
+	}
+}
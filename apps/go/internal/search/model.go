@@ -0,0 +1,278 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/layout"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// OpenMsg asks for the invocation opened at a result: section, and
+// the body line to show, -1 for the section's head.
+type OpenMsg struct {
+	Section int
+	Line    int
+}
+
+// PageMsg asks for the manifesto opened at a line past the invocation.
+type PageMsg struct {
+	Line int
+}
+
+// Model is the search view: a query prompt over ranked results, each
+// its place and a snippet with the matches picked out.
+type Model struct {
+	index    *Index
+	query    textinput.Model
+	results  []Result
+	cursor   int
+	offset   int
+	keys     keys.Map
+	help     help.Model
+	theme    theme.Theme
+	width    int
+	height   int
+	ready    bool
+	renderer *lipgloss.Renderer
+}
+
+// New opens the search view over ix, ready for typing.
+func New(r *lipgloss.Renderer, ix *Index, th theme.Theme, km keys.Map) Model {
+	query := textinput.New()
+	query.Prompt = "/ "
+	query.Placeholder = "a word or phrase"
+	query.Focus()
+	return Model{
+		index:    ix,
+		query:    query,
+		keys:     km.Or(),
+		help:     keys.NewHelp(r, th),
+		theme:    th,
+		renderer: r,
+	}
+}
+
+// Capturing reports whether keystrokes are going into the query.
+func (m Model) Capturing() bool {
+	return m.query.Focused()
+}
+
+// Resume comes back ready to type again, the last results still shown.
+func (m Model) Resume() (tea.Model, tea.Cmd) {
+	return m, m.query.Focus()
+}
+
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case theme.ChangedMsg:
+		m.theme = msg.Theme
+		m.help = keys.NewHelp(m.renderer, m.theme)
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+		m.query.Width = m.contentWidth() - 3
+		m.moveCursor(0)
+		return m, nil
+
+	case tea.MouseMsg:
+		if msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.moveCursor(-1)
+		case tea.MouseButtonWheelDown:
+			m.moveCursor(1)
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.query.Focused() {
+			return m.updateQuery(msg)
+		}
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, m.keys.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, m.keys.Select):
+			return m, m.open()
+		case key.Matches(msg, m.keys.Search):
+			return m, m.query.Focus()
+		}
+		return m, nil
+	}
+
+	if m.query.Focused() {
+		var cmd tea.Cmd
+		m.query, cmd = m.query.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// updateQuery types into the query, searching as it changes. The
+// arrows move through results meanwhile; esc leaves the results to
+// browse with the list keys.
+func (m Model) updateQuery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "enter":
+		return m, m.open()
+	case "esc":
+		m.query.Blur()
+		return m, nil
+	case "up", "ctrl+p":
+		m.moveCursor(-1)
+		return m, nil
+	case "down", "ctrl+n":
+		m.moveCursor(1)
+		return m, nil
+	}
+	before := m.query.Value()
+	var cmd tea.Cmd
+	m.query, cmd = m.query.Update(msg)
+	if m.query.Value() != before {
+		m.results = m.index.Search(m.query.Value())
+		m.cursor, m.offset = 0, 0
+	}
+	return m, cmd
+}
+
+// open asks for the selected result in the invocation, or past it in
+// the manifesto.
+func (m Model) open() tea.Cmd {
+	if m.cursor >= len(m.results) {
+		return nil
+	}
+	r := m.results[m.cursor]
+	return func() tea.Msg {
+		if r.Kind == Heading || r.Kind == Page {
+			return PageMsg{Line: r.Line}
+		}
+		return OpenMsg{Section: r.Section, Line: r.Line}
+	}
+}
+
+// moveCursor moves the selection by delta, scrolling to keep it shown.
+func (m *Model) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.results)-1))
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+}
+
+func (m Model) contentWidth() int {
+	_, x := m.padding()
+	return max(10, min(m.width-2*x, 72))
+}
+
+// listHeight is how many results fit, at two rows each.
+func (m Model) listHeight() int {
+	y, _ := m.padding()
+	return max(1, (m.height-6-2*y)/2)
+}
+
+// padding is the space around the view, dropped to a column on a
+// narrow or short terminal.
+func (m Model) padding() (y, x int) {
+	if layout.Narrow(m.width) || layout.Short(m.height) {
+		return 0, 1
+	}
+	return 1, 2
+}
+
+func (m Model) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	th := m.theme
+	quiet := theme.Degraded(r)
+	titleStyle := r.NewStyle().Foreground(th.Accent).Bold(true)
+	headStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+	textStyle := r.NewStyle().Foreground(th.Text)
+	cursorStyle := r.NewStyle().Foreground(th.Title).Bold(true)
+	hitStyle := r.NewStyle().Foreground(th.Highlight).Bold(true).Underline(th.NoColor)
+
+	w := m.contentWidth()
+	help := m.help
+	help.Width = w
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("॥ SEARCH ॥"))
+	if m.query.Value() != "" {
+		b.WriteString(headStyle.Render(fmt.Sprintf("  %d found", len(m.results))))
+	}
+	b.WriteString("\n\n")
+	b.WriteString(m.query.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case m.query.Value() == "":
+		b.WriteString(headStyle.Width(w).Render("Titles, key lines, the text, reflections and notes, then the rituals and the rest of the manifesto."))
+		b.WriteString("\n")
+	case len(m.results) == 0:
+		b.WriteString(textStyle.Render("Nothing matches."))
+		b.WriteString("\n")
+	}
+
+	h := m.listHeight()
+	for i := m.offset; i < len(m.results) && i < m.offset+h; i++ {
+		res := m.results[i]
+		place := res.Place()
+		if len([]rune(place)) > w-2 {
+			place = string([]rune(place)[:w-3]) + "…"
+		}
+		text, matches := res.Snippet(w - 2)
+		base := textStyle
+		if i == m.cursor {
+			b.WriteString(cursorStyle.Render("► " + place))
+			base = base.Foreground(th.Bright)
+		} else {
+			b.WriteString(headStyle.Render("  " + place))
+		}
+		b.WriteString("\n  ")
+		b.WriteString(Mark(text, matches, func(piece string, hit bool) string {
+			if hit {
+				return hitStyle.Render(piece)
+			}
+			return base.Render(piece)
+		}))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.query.Focused() {
+		b.WriteString(headStyle.Render("enter open · ↑/↓ choose · esc browse"))
+	} else {
+		b.WriteString(help.ShortHelpView(m.keys.SearchView().ShortHelp()))
+	}
+
+	return r.NewStyle().Padding(m.padding()).Render(b.String())
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+)
+
+// Place says where a result is: its section and what it is there, or
+// past the invocation, the headings over it.
+func (r Result) Place() string {
+	switch r.Kind {
+	case Part:
+		return "Part I"
+	case Heading:
+		if r.Where == "" {
+			return "Manifesto"
+		}
+		return r.Where
+	case Page:
+		if r.Where == "" {
+			return fmt.Sprintf("Manifesto · line %d", r.Line+1)
+		}
+		return fmt.Sprintf("%s · line %d", r.Where, r.Line+1)
+	}
+	head := fmt.Sprintf("%d %s", r.Section+1, invocation.Sections()[r.Section].Title)
+	switch r.Kind {
+	case KeyLine:
+		return head + " · key line"
+	case Line:
+		return fmt.Sprintf("%s · line %d", head, r.Line+1)
+	case Prompt:
+		return head + " · reflection"
+	case Note:
+		return head + " · note"
+	}
+	return head
+}
+
+// Snippet is the result's text cut to at most width characters around
+// its first match, with an ellipsis where it was cut, and the matches
+// moved to fit.
+func (r Result) Snippet(width int) (string, [][2]int) {
+	text := r.Text
+	if width <= 1 || utf8.RuneCountInString(text) <= width {
+		return text, r.Matches
+	}
+
+	// Start a third of the way before the first match, at a word
+	from := 0
+	if len(r.Matches) > 0 {
+		lead := r.Matches[0][0]
+		for back := width / 3; back > 0 && lead > 0; back-- {
+			_, n := utf8.DecodeLastRuneInString(text[:lead])
+			lead -= n
+		}
+		if i := strings.IndexByte(text[lead:], ' '); lead > 0 && i >= 0 && i < width/3 {
+			lead += i + 1
+		}
+		from = lead
+	}
+
+	prefix, budget := "", width
+	if from > 0 {
+		prefix, budget = "…", width-1
+	}
+	to := from
+	for n := 0; to < len(text) && n < budget; n++ {
+		_, size := utf8.DecodeRuneInString(text[to:])
+		to += size
+	}
+	suffix := ""
+	if to < len(text) {
+		// Make room for the ellipsis
+		_, size := utf8.DecodeLastRuneInString(text[:to])
+		to -= size
+		suffix = "…"
+	}
+
+	var matches [][2]int
+	shift := len(prefix) - from
+	for _, m := range r.Matches {
+		if m[0] < from || m[1] > to {
+			continue
+		}
+		matches = append(matches, [2]int{m[0] + shift, m[1] + shift})
+	}
+	return prefix + text[from:to] + suffix, matches
+}
+
+// Mark joins text back together with f applied to every piece, told
+// whether the piece is a match.
+func Mark(text string, matches [][2]int, f func(piece string, match bool) string) string {
+	var b strings.Builder
+	at := 0
+	for _, m := range matches {
+		if m[0] < at {
+			continue
+		}
+		if m[0] > at {
+			b.WriteString(f(text[at:m[0]], false))
+		}
+		b.WriteString(f(text[m[0]:m[1]], true))
+		at = m[1]
+	}
+	if at < len(text) {
+		b.WriteString(f(text[at:], false))
+	}
+	return b.String()
+}
@@ -0,0 +1,303 @@
+// Package server serves the reader over SSH and over a browser terminal.
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/charmbracelet/wish/bubbletea"
+	"github.com/charmbracelet/wish/logging"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/gorkolas/cybertantra/internal/app"
+	"github.com/gorkolas/cybertantra/internal/datadir"
+	"github.com/gorkolas/cybertantra/internal/highlight"
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/journal"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// SSHConfig configures the SSH server.
+type SSHConfig struct {
+	Addr    string // Listen address, e.g. "0.0.0.0:2222"
+	HostKey string // Host key path, generated when missing
+}
+
+// sshServer holds what every session shares: the builtins plus the
+// server's user themes, its circadian schedule and its key bindings,
+// and the highlights of every key with a session open.
+type sshServer struct {
+	themes     []theme.Theme
+	circadian  theme.Circadian
+	keys       keys.Map
+	highlights *highlightStores
+}
+
+// highlightStores is one highlight.Store per data directory, so two
+// sessions with the same key mark through one store rather than each
+// rewriting the file from its own copy. A store is dropped when the
+// last session using it closes.
+type highlightStores struct {
+	mu     sync.Mutex
+	stores map[string]*sharedStore
+}
+
+// sharedStore is an open store and how many sessions hold it.
+type sharedStore struct {
+	*highlight.Store
+	sessions int
+}
+
+// ServeSSH runs the SSH server until interrupted.
+func ServeSSH(cfg SSHConfig) error {
+	srv := sshServer{highlights: &highlightStores{stores: make(map[string]*sharedStore)}}
+	var err error
+	if srv.themes, err = theme.All(theme.Dir()); err != nil {
+		log.Warn("Could not load themes", "error", err)
+	}
+	if srv.circadian, err = theme.LoadCircadian(theme.CircadianPath()); err != nil {
+		log.Warn("Could not load circadian schedule", "error", err)
+	}
+	if srv.keys, err = keys.Load(keys.Path()); err != nil {
+		log.Warn("Could not load key bindings", "error", err)
+	}
+
+	s, err := wish.NewServer(
+		wish.WithAddress(cfg.Addr),
+		wish.WithHostKeyPath(cfg.HostKey),
+		// Any key is welcome; it only identifies whose journal to open.
+		wish.WithPublicKeyAuth(func(ssh.Context, ssh.PublicKey) bool { return true }),
+		wish.WithKeyboardInteractiveAuth(func(ssh.Context, gossh.KeyboardInteractiveChallenge) bool { return true }),
+		wish.WithMiddleware(
+			bubbletea.Middleware(srv.teaHandler),
+			srv.accessible, // Runs first: the last middleware is the outermost
+			logging.Middleware(),
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	log.Info("Starting SSH server", "addr", cfg.Addr)
+	log.Info("Connect with: ssh -p " + portOf(cfg.Addr) + " localhost")
+
+	errc := make(chan error, 1)
+	go func() {
+		if err := s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+			errc <- err
+		}
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-done:
+	}
+	log.Info("Stopping SSH server")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (srv sshServer) teaHandler(s ssh.Session) (tea.Model, []tea.ProgramOption) {
+	renderer := bubbletea.MakeRenderer(s)
+	// Detection reads the client's TERM, COLORTERM and NO_COLOR;
+	// CYBERTANTRA_COLOR sent with SetEnv overrides it.
+	list := srv.themes
+	if theme.Prepare(renderer, sessionEnv(s, theme.ColorEnvVar)) {
+		list = theme.PlainAll(srv.themes)
+	}
+	th, _ := theme.Resolve(list, sessionEnv(s, theme.EnvVar))
+
+	// The schedule follows the client's clock when it sends TZ
+	loc := time.Local
+	if tz := sessionEnv(s, "TZ"); tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+
+	var palettes *theme.Log
+	var j *journal.Journal
+	var hs *highlight.Store
+	var progress, pace string
+	if dir, ok := sessionDir(s); ok {
+		palettes = theme.OpenLog(filepath.Join(dir, "palette.jsonl"))
+		progress = filepath.Join(dir, "invocation.json")
+		pace = filepath.Join(dir, "pace.json")
+		j = sessionJournal(s, dir)
+		hs = srv.sessionHighlights(s, dir)
+		go func() {
+			<-s.Context().Done()
+			palettes.Record("", theme.SourceEnd)
+		}()
+	}
+
+	m := app.New(renderer, app.Options{
+		Journal:    j,
+		Theme:      th,
+		Themes:     list,
+		Keys:       srv.keys,
+		Circadian:  srv.circadian.Override(sessionEnv(s, theme.CircadianEnvVar)),
+		Location:   loc,
+		PaletteLog: palettes,
+		Progress:   progress,
+		Pace:       pace,
+		Highlights: hs,
+		Links:      theme.Links(func(key string) string { return sessionTerm(s, key) }),
+	})
+	return m, []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+}
+
+// accessible serves the linear reader, for screen readers and braille
+// displays, to a client that asks for it: ssh -p 2222 localhost
+// accessible, or CYBERTANTRA_ACCESSIBLE=1 sent with SetEnv.
+func (srv sshServer) accessible(next ssh.Handler) ssh.Handler {
+	return func(s ssh.Session) {
+		cmd := s.Command()
+		asked := len(cmd) == 1 && cmd[0] == "accessible"
+		if !asked && !invocation.Accessible(sessionEnv(s, invocation.AccessibleEnvVar)) {
+			next(s)
+			return
+		}
+		var opts invocation.Options
+		if dir, ok := sessionDir(s); ok {
+			opts.Journal = sessionJournal(s, dir)
+			opts.Progress = filepath.Join(dir, "invocation.json")
+			opts.Highlights = srv.sessionHighlights(s, dir)
+		}
+		// Without -t there is no terminal and the client's own line
+		// editing does the echo; with one the client is in raw mode, so
+		// the reader echoes and edits lines itself, and lines need a
+		// carriage return
+		var out io.Writer = s
+		if _, _, ok := s.Pty(); ok {
+			out = crlfWriter{s}
+			opts.Echo = true
+		}
+		if err := invocation.ReadLinear(s, out, 0, opts); err != nil {
+			log.Error("Accessible reader failed", "user", s.User(), "error", err)
+		}
+	}
+}
+
+// crlfWriter ends lines with \r\n for a terminal in raw mode.
+type crlfWriter struct{ w io.Writer }
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// sessionEnv looks up a variable the client sent, such as
+// CYBERTANTRA_THEME via ssh -o SetEnv=CYBERTANTRA_THEME=ghibli.
+func sessionEnv(s ssh.Session, key string) string {
+	for _, kv := range s.Environ() {
+		if v, ok := strings.CutPrefix(kv, key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// sessionTerm looks up a variable describing the client's terminal:
+// TERM comes with the pty request, the rest only if the client sent
+// them.
+func sessionTerm(s ssh.Session, key string) string {
+	if key == "TERM" {
+		if pty, _, ok := s.Pty(); ok {
+			return pty.Term
+		}
+	}
+	return sessionEnv(s, key)
+}
+
+// sessionDir is the data directory belonging to the session's public
+// key. Keyless sessions have none, so read without a journal or log.
+func sessionDir(s ssh.Session) (string, bool) {
+	key := s.PublicKey()
+	if key == nil {
+		return "", false
+	}
+	sum := sha256.Sum256(key.Marshal())
+	dir := datadir.UserDir(hex.EncodeToString(sum[:8]))
+	return dir, os.MkdirAll(dir, 0700) == nil
+}
+
+// sessionJournal opens the journal in the session's data directory.
+func sessionJournal(s ssh.Session, dir string) *journal.Journal {
+	j, err := journal.Open(filepath.Join(dir, "journal"))
+	if err != nil {
+		log.Error("Could not open journal", "user", s.User(), "error", err)
+		return nil
+	}
+	return j
+}
+
+// sessionHighlights opens the highlights in the session's data
+// directory, or shares those already open for another session there,
+// until the session closes.
+func (srv sshServer) sessionHighlights(s ssh.Session, dir string) *highlight.Store {
+	hs := srv.highlights
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	shared, ok := hs.stores[dir]
+	if !ok {
+		store, err := highlight.Open(filepath.Join(dir, "highlights.json"))
+		if err != nil {
+			log.Error("Could not open highlights", "user", s.User(), "error", err)
+			return nil
+		}
+		shared = &sharedStore{Store: store}
+		hs.stores[dir] = shared
+	}
+	shared.sessions++
+	go func() {
+		<-s.Context().Done()
+		hs.release(dir)
+	}()
+	return shared.Store
+}
+
+// release lets go of one session's hold on dir's store, dropping it
+// with the last.
+func (hs *highlightStores) release(dir string) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if shared, ok := hs.stores[dir]; ok {
+		if shared.sessions--; shared.sessions <= 0 {
+			delete(hs.stores, dir)
+		}
+	}
+}
+
+func portOf(addr string) string {
+	if i := strings.LastIndex(addr, ":"); i >= 0 {
+		return addr[i+1:]
+	}
+	return addr
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/creack/pty"
+	"github.com/gorilla/websocket"
+)
+
+type resizeMsg struct {
+	Cols uint16 `json:"cols"`
+	Rows uint16 `json:"rows"`
+}
+
+//go:embed static
+var staticFiles embed.FS
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true // Allow all origins for dev
+	},
+}
+
+// WebConfig configures the browser terminal.
+type WebConfig struct {
+	Addr    string   // Listen address, e.g. ":8080"
+	Command []string // Reader started in a PTY per connection
+}
+
+type webServer struct {
+	command []string
+}
+
+// ServeWeb runs the browser terminal until interrupted. Every visitor
+// gets their own PTY running cfg.Command.
+func ServeWeb(cfg WebConfig) error {
+	if len(cfg.Command) == 0 {
+		return errors.New("no reader command to run")
+	}
+	srv := webServer{command: cfg.Command}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", serveIndex)
+	mux.HandleFunc("/ws", srv.handleWebSocket)
+
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+
+	log.Printf("Starting web server on http://localhost:%s", portOf(cfg.Addr))
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- http.ListenAndServe(cfg.Addr, mux)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-done:
+	}
+	log.Println("Shutting down")
+	return nil
+}
+
+func serveIndex(w http.ResponseWriter, r *http.Request) {
+	data, err := staticFiles.ReadFile("static/index.html")
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.Write(data)
+}
+
+func (srv webServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Websocket upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Start the TUI in a PTY
+	cmd := exec.Command(srv.command[0], srv.command[1:]...)
+	// xterm.js renders truecolor; ?color=16 or ?color=none tries a basic terminal
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color", "COLORTERM=truecolor")
+	if c := r.URL.Query().Get("color"); c != "" {
+		cmd.Env = append(cmd.Env, "CYBERTANTRA_COLOR="+c)
+	}
+
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		log.Printf("PTY start error: %v", err)
+		conn.WriteMessage(websocket.TextMessage, []byte("Error starting terminal: "+err.Error()))
+		return
+	}
+	defer func() {
+		ptmx.Close()
+		cmd.Process.Kill()
+	}()
+
+	// Set initial size
+	pty.Setsize(ptmx, &pty.Winsize{Rows: 24, Cols: 80})
+
+	// PTY -> WebSocket
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := ptmx.Read(buf)
+			if err != nil {
+				if err != io.EOF {
+					log.Printf("PTY read error: %v", err)
+				}
+				conn.Close()
+				return
+			}
+			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil {
+				log.Printf("WebSocket write error: %v", err)
+				return
+			}
+		}
+	}()
+
+	// WebSocket -> PTY
+	for {
+		msgType, msg, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		switch msgType {
+		case websocket.TextMessage:
+			// Check for resize message
+			if len(msg) > 0 && msg[0] == '{' {
+				var resize resizeMsg
+				if err := json.Unmarshal(msg, &resize); err == nil && resize.Cols > 0 && resize.Rows > 0 {
+					pty.Setsize(ptmx, &pty.Winsize{
+						Rows: resize.Rows,
+						Cols: resize.Cols,
+					})
+					continue // Don't write resize message to PTY
+				}
+			}
+			fallthrough
+		case websocket.BinaryMessage:
+			if _, err := ptmx.Write(msg); err != nil {
+				log.Printf("PTY write error: %v", err)
+				return
+			}
+		}
+	}
+}
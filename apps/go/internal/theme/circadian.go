@@ -0,0 +1,198 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+)
+
+// Circadian shifts the palette with the local time of day: cool and
+// sattvic in the morning, the practitioner's own theme through the day,
+// warm amber after dark.
+type Circadian struct {
+	Enabled  bool     `json:"enabled"`
+	Schedule []Period `json:"schedule"`
+	Sunrise  string   `json:"sunrise,omitempty"`             // "06:30" replaces the estimate
+	Sunset   string   `json:"sunset,omitempty"`              // "19:45" replaces the estimate
+	South    bool     `json:"southern_hemisphere,omitempty"` // Flips the seasons of the estimate
+}
+
+// Period starts a theme at a time of day: "sunrise", "sunset", either
+// with an offset ("sunset-1h", "sunrise+4h30m"), or a clock time
+// ("22:00"). An empty theme means the one the practitioner chose.
+type Period struct {
+	At    string `json:"at"`
+	Theme string `json:"theme"`
+}
+
+var atPattern = regexp.MustCompile(`^(sunrise|sunset)([+-].+)?$`)
+
+// DefaultCircadian is the schedule used until circadian.json says
+// otherwise, disabled until the practitioner opts in.
+func DefaultCircadian() Circadian {
+	return Circadian{
+		Schedule: []Period{
+			{At: "sunrise", Theme: Dawn.Name},
+			{At: "sunrise+4h", Theme: ""},
+			{At: "sunset", Theme: Amber.Name},
+		},
+	}
+}
+
+// CircadianEnvVar turns the schedule on or off for one session,
+// whatever the file says: 1/on/true or 0/off/false.
+const CircadianEnvVar = "CYBERTANTRA_CIRCADIAN"
+
+// Override applies a CircadianEnvVar value to c.
+func (c Circadian) Override(v string) Circadian {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "1", "on", "true", "yes":
+		c.Enabled = true
+	case "0", "off", "false", "no":
+		c.Enabled = false
+	}
+	return c
+}
+
+// CircadianPath is where the schedule lives.
+func CircadianPath() string {
+	return datadir.Path("circadian.json")
+}
+
+// LoadCircadian reads a schedule. A missing file is a disabled default.
+func LoadCircadian(path string) (Circadian, error) {
+	c := DefaultCircadian()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return DefaultCircadian(), fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.Validate(); err != nil {
+		return DefaultCircadian(), fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Validate checks every period's time parses.
+func (c Circadian) Validate() error {
+	if len(c.Schedule) == 0 && c.Enabled {
+		return fmt.Errorf("empty schedule")
+	}
+	day := time.Date(2000, 6, 21, 0, 0, 0, 0, time.Local)
+	for _, p := range c.Schedule {
+		if _, err := c.start(p.At, day); err != nil {
+			return err
+		}
+	}
+	for _, s := range []string{c.Sunrise, c.Sunset} {
+		if s != "" {
+			if _, err := clock(s, day); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SunTimes estimates sunrise and sunset on t's day without knowing
+// where the practitioner is: day length swings ±3.5h around 12h over
+// the year (about 45° latitude), centred on solar noon at 12:00, or
+// 13:00 under daylight saving. Sunrise and Sunset override it.
+func (c Circadian) SunTimes(t time.Time) (rise, set time.Time) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+
+	swing := math.Cos(2 * math.Pi * float64(t.YearDay()-172) / 365.25)
+	if c.South {
+		swing = -swing
+	}
+	half := time.Duration((12 + 3.5*swing) / 2 * float64(time.Hour))
+	noon := day.Add(12 * time.Hour)
+	if t.IsDST() {
+		noon = noon.Add(time.Hour)
+	}
+	rise, set = noon.Add(-half).Truncate(time.Minute), noon.Add(half).Truncate(time.Minute)
+
+	if r, err := clock(c.Sunrise, day); c.Sunrise != "" && err == nil {
+		rise = r
+	}
+	if s, err := clock(c.Sunset, day); c.Sunset != "" && err == nil {
+		set = s
+	}
+	return rise, set
+}
+
+// At returns the theme name scheduled at t; "" is the chosen theme.
+// Before the day's first period, the last one still holds from the
+// night before.
+func (c Circadian) At(t time.Time) string {
+	type start struct {
+		at    time.Time
+		theme string
+	}
+	var starts []start
+	for _, p := range c.Schedule {
+		at, err := c.start(p.At, t)
+		if err != nil {
+			continue
+		}
+		starts = append(starts, start{at, p.Theme})
+	}
+	if len(starts) == 0 {
+		return ""
+	}
+	sort.SliceStable(starts, func(a, b int) bool {
+		return starts[a].at.Before(starts[b].at)
+	})
+
+	name := starts[len(starts)-1].theme
+	for _, s := range starts {
+		if s.at.After(t) {
+			break
+		}
+		name = s.theme
+	}
+	return name
+}
+
+// start resolves a period's At on t's day.
+func (c Circadian) start(spec string, t time.Time) (time.Time, error) {
+	spec = strings.ToLower(strings.ReplaceAll(spec, " ", ""))
+	m := atPattern.FindStringSubmatch(spec)
+	if m == nil {
+		return clock(spec, t)
+	}
+	rise, set := c.SunTimes(t)
+	base := rise
+	if m[1] == "sunset" {
+		base = set
+	}
+	if m[2] == "" {
+		return base, nil
+	}
+	off, err := time.ParseDuration(m[2])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: bad offset: %w", spec, err)
+	}
+	return base.Add(off), nil
+}
+
+// clock reads "HH:MM" on t's day.
+func clock(s string, t time.Time) (time.Time, error) {
+	hm, err := time.Parse("15:04", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q: want sunrise, sunset or HH:MM", s)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), hm.Hour(), hm.Minute(), 0, 0, t.Location()), nil
+}
@@ -0,0 +1,171 @@
+package theme
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+)
+
+// file is the on-disk form of a user theme. Roles left out are taken
+// from base, or from the default theme.
+//
+//	name = "Temple Dusk"
+//	base = "ghibli"
+//	accent = "#ffb86c"
+type file struct {
+	Name      string `json:"name" toml:"name"`
+	Base      string `json:"base" toml:"base"`
+	Title     string `json:"title" toml:"title"`
+	Accent    string `json:"accent" toml:"accent"`
+	Highlight string `json:"highlight" toml:"highlight"`
+	Success   string `json:"success" toml:"success"`
+	Flash     string `json:"flash" toml:"flash"`
+	Bright    string `json:"bright" toml:"bright"`
+	Text      string `json:"text" toml:"text"`
+	Faded     string `json:"faded" toml:"faded"`
+	Muted     string `json:"muted" toml:"muted"`
+	Dim       string `json:"dim" toml:"dim"`
+}
+
+// A colour is a hex value or an ANSI index (0-255).
+var colorPattern = regexp.MustCompile(`^(#[0-9a-fA-F]{6}|#[0-9a-fA-F]{3}|25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])$`)
+
+// EnvVar names the theme to start with.
+const EnvVar = "CYBERTANTRA_THEME"
+
+// Dir is where user themes live.
+func Dir() string {
+	return datadir.Path("themes")
+}
+
+// LoadDir reads every .toml and .json theme in dir, sorted by file name.
+// A missing dir is not an error; a broken file is reported and skipped.
+func LoadDir(dir string) ([]Theme, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var themes []Theme
+	var errs []error
+	for _, p := range paths {
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".toml" && ext != ".json" {
+			continue
+		}
+		t, err := LoadFile(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		themes = append(themes, t)
+	}
+	return themes, errors.Join(errs...)
+}
+
+// LoadFile reads one user theme, picking the format from the extension.
+func LoadFile(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	var f file
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &f)
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	default:
+		err = fmt.Errorf("unknown theme format")
+	}
+	if err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Name == "" {
+		f.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	base := Default()
+	if f.Base != "" {
+		b, ok := Find(Builtin(), f.Base)
+		if !ok {
+			return Theme{}, fmt.Errorf("%s: unknown base theme %q", path, f.Base)
+		}
+		base = b
+	}
+
+	t := base
+	t.Name = f.Name
+	for _, role := range []struct {
+		name  string
+		value string
+		dst   *lipgloss.Color
+	}{
+		{"title", f.Title, &t.Title},
+		{"accent", f.Accent, &t.Accent},
+		{"highlight", f.Highlight, &t.Highlight},
+		{"success", f.Success, &t.Success},
+		{"flash", f.Flash, &t.Flash},
+		{"bright", f.Bright, &t.Bright},
+		{"text", f.Text, &t.Text},
+		{"faded", f.Faded, &t.Faded},
+		{"muted", f.Muted, &t.Muted},
+		{"dim", f.Dim, &t.Dim},
+	} {
+		if role.value == "" {
+			continue
+		}
+		if !colorPattern.MatchString(role.value) {
+			return Theme{}, fmt.Errorf("%s: %s: %q is not a hex colour or ANSI index (0-255)", path, role.name, role.value)
+		}
+		*role.dst = lipgloss.Color(role.value)
+	}
+	return t, nil
+}
+
+// All returns the builtin themes followed by the user's from dir. A user
+// theme with a builtin's name replaces it in place.
+func All(dir string) ([]Theme, error) {
+	themes := Builtin()
+	user, err := LoadDir(dir)
+	for _, u := range user {
+		replaced := false
+		for i, t := range themes {
+			if normalize(t.Name) == normalize(u.Name) {
+				themes[i] = u
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			themes = append(themes, u)
+		}
+	}
+	return themes, err
+}
+
+// Resolve picks the named theme from themes, falling back to the first.
+func Resolve(themes []Theme, name string) (Theme, error) {
+	if len(themes) == 0 {
+		themes = Builtin()
+	}
+	if name == "" {
+		return themes[0], nil
+	}
+	t, ok := Find(themes, name)
+	if !ok {
+		return themes[0], fmt.Errorf("unknown theme %q", name)
+	}
+	return t, nil
+}
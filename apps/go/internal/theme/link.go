@@ -0,0 +1,65 @@
+package theme
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LinkEnvVar turns clickable links on or off instead of guessing from
+// the terminal: 1/on/true or 0/off/false.
+const LinkEnvVar = "CYBERTANTRA_LINKS"
+
+// linkPrograms are TERM_PROGRAM values of terminals known to open OSC 8
+// hyperlinks.
+var linkPrograms = []string{"iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper", "Tabby", "rio"}
+
+// linkTerms are TERM values, in part, of terminals known to open them.
+var linkTerms = []string{"kitty", "foot", "alacritty", "ghostty", "wezterm", "contour", "rio"}
+
+// Links reports whether the terminal that env describes shows OSC 8
+// hyperlinks. A terminal without them prints the escape as text, and
+// none says which it is, so Links guesses from the variables the known
+// ones set. LinkEnvVar settles it either way.
+func Links(env func(string) string) bool {
+	switch strings.ToLower(strings.TrimSpace(env(LinkEnvVar))) {
+	case "1", "on", "true", "yes":
+		return true
+	case "0", "off", "false", "no":
+		return false
+	}
+	for _, p := range linkPrograms {
+		if env("TERM_PROGRAM") == p {
+			return true
+		}
+	}
+	if env("KITTY_WINDOW_ID") != "" || env("WT_SESSION") != "" || env("KONSOLE_VERSION") != "" {
+		return true
+	}
+	// GNOME Terminal and the other VTE terminals, from 0.50
+	if v, err := strconv.Atoi(env("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	term := env("TERM")
+	for _, t := range linkTerms {
+		if strings.Contains(term, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hyperlink is text the terminal opens url from when it is clicked,
+// as an OSC 8 escape. Control characters are dropped from both, so
+// neither can end the escape early.
+func Hyperlink(url, text string) string {
+	return "\x1b]8;;" + printable(url) + "\x1b\\" + printable(text) + "\x1b]8;;\x1b\\"
+}
+
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
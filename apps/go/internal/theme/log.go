@@ -0,0 +1,93 @@
+package theme
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// Why a palette became active.
+const (
+	SourceStart     = "start"     // Session opened
+	SourceCircadian = "circadian" // Schedule moved on
+	SourceManual    = "manual"    // Practitioner pressed t
+	SourceEnd       = "end"       // Session closed; no palette until the next start
+)
+
+// LogEntry records a palette becoming active.
+type LogEntry struct {
+	Time   time.Time `json:"time"`
+	Theme  string    `json:"theme,omitempty"`
+	Source string    `json:"source"`
+}
+
+// Log is an append-only JSONL record of which palette was on screen,
+// kept so its effect can be read against journal moods.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// OpenLog uses the log at path, creating it on first write.
+func OpenLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Record appends an entry stamped now. A nil Log records nothing.
+func (l *Log) Record(theme, source string) error {
+	if l == nil {
+		return nil
+	}
+	data, err := json.Marshal(LogEntry{Time: time.Now(), Theme: theme, Source: source})
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Entries returns the log, oldest first.
+func (l *Log) Entries() ([]LogEntry, error) {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []LogEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e LogEntry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// ActiveAt returns the palette on screen at t, if a session was open.
+// Entries must be oldest first.
+func ActiveAt(entries []LogEntry, t time.Time) (string, bool) {
+	name, ok := "", false
+	for _, e := range entries {
+		if e.Time.After(t) {
+			break
+		}
+		name, ok = e.Theme, e.Source != SourceEnd
+	}
+	return name, ok
+}
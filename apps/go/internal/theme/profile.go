@@ -0,0 +1,61 @@
+package theme
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ColorEnvVar forces a colour profile instead of detecting one:
+// truecolor, 256, 16 or none. NO_COLOR is honoured by detection itself.
+const ColorEnvVar = "CYBERTANTRA_COLOR"
+
+// ParseProfile reads a ColorEnvVar value.
+func ParseProfile(s string) (termenv.Profile, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "truecolor", "24bit", "true":
+		return termenv.TrueColor, true
+	case "256", "ansi256":
+		return termenv.ANSI256, true
+	case "16", "ansi", "8":
+		return termenv.ANSI, true
+	case "none", "mono", "ascii", "0":
+		return termenv.Ascii, true
+	}
+	return termenv.Ascii, false
+}
+
+// Prepare settles r's colour profile for a session: override, when it
+// names one, replaces detection. It reports whether the terminal gets
+// no colour, in which case r is moved up to 16 colours so bold, faint
+// and underline still render, and callers must hand out Plain themes.
+func Prepare(r *lipgloss.Renderer, override string) (noColor bool) {
+	if p, ok := ParseProfile(override); ok {
+		r.SetColorProfile(p)
+	}
+	if r.ColorProfile() != termenv.Ascii {
+		return false
+	}
+	r.SetColorProfile(termenv.ANSI)
+	return true
+}
+
+// PlainAll strips the colour from every theme.
+func PlainAll(themes []Theme) []Theme {
+	out := make([]Theme, len(themes))
+	for i, t := range themes {
+		out[i] = t.Plain()
+	}
+	return out
+}
+
+// Degraded reports whether r is too coarse for a theme's greys to stay
+// apart (16 colours or none), so views should lean on bold, faint and
+// underline instead.
+func Degraded(r *lipgloss.Renderer) bool {
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	return r.ColorProfile() >= termenv.ANSI
+}
@@ -0,0 +1,174 @@
+// Package theme holds the palettes every view draws its styles from.
+package theme
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme assigns a colour to each role the views use. Roles are named
+// for what they do, not their hue, so a light theme can swap them freely.
+type Theme struct {
+	Name      string
+	Title     lipgloss.Color // Section titles, the selected item
+	Accent    lipgloss.Color // Key lines, headings, bold emphasis
+	Highlight lipgloss.Color // Tags, rules, warnings
+	Success   lipgloss.Color // Confirmations
+	Flash     lipgloss.Color // Text the moment it is revealed
+	Bright    lipgloss.Color // Fully revealed body text
+	Text      lipgloss.Color // Body text, menu items
+	Faded     lipgloss.Color // Descriptions, hints, fading lines
+	Muted     lipgloss.Color // Subtitles, footers
+	Dim       lipgloss.Color // Lines just starting to appear
+
+	NoColor bool // Set by Plain: every role is empty, views use attributes
+}
+
+// Plain keeps t's name but drops every colour, for NO_COLOR terminals.
+func (t Theme) Plain() Theme {
+	return Theme{Name: t.Name, NoColor: true}
+}
+
+// ChangedMsg tells views to rebuild their styles from a new theme.
+type ChangedMsg struct {
+	Theme Theme
+}
+
+// Builtin themes, in switching order. Light assumes a light terminal
+// background; the others a dark one.
+var (
+	Neon = Theme{
+		Name:      "Neon CRT",
+		Title:     "#5ad4ff",
+		Accent:    "#ffef7c",
+		Highlight: "#ff66cc",
+		Success:   "#6dd835",
+		Flash:     "#ffffff",
+		Bright:    "#f0f0f0",
+		Text:      "#d0d0d0",
+		Faded:     "#909090",
+		Muted:     "#707070",
+		Dim:       "#505050",
+	}
+	Ghibli = Theme{
+		Name:      "Ghibli Soft",
+		Title:     "#8fc1a9",
+		Accent:    "#f2c879",
+		Highlight: "#e8a3b0",
+		Success:   "#a5cf8b",
+		Flash:     "#fffaf0",
+		Bright:    "#f4efe6",
+		Text:      "#d9d2c5",
+		Faded:     "#a89f91",
+		Muted:     "#857d70",
+		Dim:       "#5e574d",
+	}
+	Cyberpunk = Theme{
+		Name:      "Cyberpunk Grit",
+		Title:     "#00fff0",
+		Accent:    "#fcee0a",
+		Highlight: "#ff003c",
+		Success:   "#39ff14",
+		Flash:     "#ffffff",
+		Bright:    "#e6e6e6",
+		Text:      "#b8c0c8",
+		Faded:     "#7a8590",
+		Muted:     "#5a6470",
+		Dim:       "#3a4250",
+	}
+	Light = Theme{
+		Name:      "Clean Light",
+		Title:     "#005f87",
+		Accent:    "#875f00",
+		Highlight: "#af005f",
+		Success:   "#2e7d32",
+		Flash:     "#000000",
+		Bright:    "#1a1a1a",
+		Text:      "#303030",
+		Faded:     "#606060",
+		Muted:     "#808080",
+		Dim:       "#a8a8a8",
+	}
+
+	// Dawn and Amber are the circadian palettes (On Blue Light): cool
+	// clarity for the morning, no blue at all after dark.
+	Dawn = Theme{
+		Name:      "Sattvic Dawn",
+		Title:     "#7fb2ff",
+		Accent:    "#c8e0ff",
+		Highlight: "#a0c4e8",
+		Success:   "#8fd3c7",
+		Flash:     "#ffffff",
+		Bright:    "#eef4fb",
+		Text:      "#c9d6e3",
+		Faded:     "#8a9bb0",
+		Muted:     "#6a7b90",
+		Dim:       "#4a5a6e",
+	}
+	Amber = Theme{
+		Name:      "Amber Night",
+		Title:     "#ff9e40",
+		Accent:    "#ffc766",
+		Highlight: "#e0703a",
+		Success:   "#c9a227",
+		Flash:     "#ffe0b0",
+		Bright:    "#f2c890",
+		Text:      "#d4a470",
+		Faded:     "#9a7048",
+		Muted:     "#7a5636",
+		Dim:       "#553a24",
+	}
+)
+
+// Builtin returns the themes that ship with cybertantra.
+func Builtin() []Theme {
+	return []Theme{Neon, Ghibli, Cyberpunk, Light, Dawn, Amber}
+}
+
+// Default is the theme used when none is chosen.
+func Default() Theme {
+	return Neon
+}
+
+// Find looks a theme up by name, ignoring case, spaces and dashes, so
+// "ghibli-soft" and "Ghibli Soft" match. A prefix is enough: "ghibli".
+func Find(themes []Theme, name string) (Theme, bool) {
+	want := normalize(name)
+	if want == "" {
+		return Theme{}, false
+	}
+	for _, t := range themes {
+		if normalize(t.Name) == want {
+			return t, true
+		}
+	}
+	for _, t := range themes {
+		if strings.HasPrefix(normalize(t.Name), want) {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Next returns the theme after current, wrapping around.
+func Next(themes []Theme, current Theme) Theme {
+	if len(themes) == 0 {
+		return current
+	}
+	for i, t := range themes {
+		if t.Name == current.Name {
+			return themes[(i+1)%len(themes)]
+		}
+	}
+	return themes[0]
+}
+
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
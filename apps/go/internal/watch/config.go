@@ -0,0 +1,212 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+)
+
+// Source kinds.
+const (
+	KindFeed    = "feed"    // RSS 2.0 or Atom URL
+	KindJSON    = "json"    // JSON endpoint with an item array
+	KindFile    = "file"    // Local file, one item per line
+	KindCommand = "command" // Shell command, one item per output line
+)
+
+// Duration is a time.Duration that reads "15m"-style strings from JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Source is one thing to poll.
+type Source struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"type"`
+	URL      string   `json:"url,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	Command  string   `json:"command,omitempty"`
+	Interval Duration `json:"interval,omitempty"`
+
+	// JSON sources: dotted paths to the item array and its fields.
+	Items string `json:"items,omitempty"`
+	Title string `json:"title,omitempty"`
+	Link  string `json:"link,omitempty"`
+	ID    string `json:"id,omitempty"`
+}
+
+// Rule raises an alert when an item matches any keyword or the regex.
+type Rule struct {
+	Name     string   `json:"name"`
+	Keywords []string `json:"keywords,omitempty"`
+	Regex    string   `json:"regex,omitempty"`
+	Sources  []string `json:"sources,omitempty"` // Empty means every source
+
+	re *regexp.Regexp
+}
+
+// Config is the watcher's rule book, stored as watch.json in the data dir.
+type Config struct {
+	Interval Duration `json:"interval"`
+	Notify   string   `json:"notify"` // osc9, osc777, both or off
+	Sources  []Source `json:"sources"`
+	Rules    []Rule   `json:"rules"`
+}
+
+// ConfigPath is where the watcher looks for its config by default.
+func ConfigPath() string {
+	return datadir.Path("watch.json")
+}
+
+// LoadConfig reads and validates a config file.
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{Interval: Duration(15 * time.Minute), Notify: "osc9"}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, cfg.validate()
+}
+
+func (c *Config) validate() error {
+	switch c.Notify {
+	case "osc9", "osc777", "both", "off":
+	default:
+		return fmt.Errorf("notify is %q, want osc9, osc777, both or off", c.Notify)
+	}
+
+	names := make(map[string]bool)
+	for i := range c.Sources {
+		s := &c.Sources[i]
+		if s.Name == "" {
+			return fmt.Errorf("source %d has no name", i+1)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate source %q", s.Name)
+		}
+		names[s.Name] = true
+		switch s.Kind {
+		case KindFeed, KindJSON:
+			if s.URL == "" {
+				return fmt.Errorf("source %q needs a url", s.Name)
+			}
+		case KindFile:
+			if s.Path == "" {
+				return fmt.Errorf("source %q needs a path", s.Name)
+			}
+		case KindCommand:
+			if s.Command == "" {
+				return fmt.Errorf("source %q needs a command", s.Name)
+			}
+		default:
+			return fmt.Errorf("source %q has unknown type %q", s.Name, s.Kind)
+		}
+		if s.Interval <= 0 {
+			s.Interval = c.Interval
+		}
+	}
+
+	for i := range c.Rules {
+		r := &c.Rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if len(r.Keywords) == 0 && r.Regex == "" {
+			return fmt.Errorf("rule %q needs keywords or a regex", r.Name)
+		}
+		if r.Regex != "" {
+			re, err := regexp.Compile(r.Regex)
+			if err != nil {
+				return fmt.Errorf("rule %q: %w", r.Name, err)
+			}
+			r.re = re
+		}
+		for _, src := range r.Sources {
+			if !names[src] {
+				return fmt.Errorf("rule %q refers to unknown source %q", r.Name, src)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether the rule fires for an item.
+func (r Rule) Match(it Item) bool {
+	if len(r.Sources) > 0 {
+		found := false
+		for _, s := range r.Sources {
+			if s == it.Source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	text := it.Title + "\n" + it.Text
+	lower := strings.ToLower(text)
+	for _, kw := range r.Keywords {
+		if strings.Contains(lower, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return r.re != nil && r.re.MatchString(text)
+}
+
+// exampleConfig is written by "watch init" as a starting point.
+const exampleConfig = `{
+  "interval": "15m",
+  "notify": "osc9",
+  "sources": [
+    {"name": "hn", "type": "feed", "url": "https://hnrss.org/frontpage", "interval": "10m"},
+    {"name": "releases", "type": "json", "url": "https://api.github.com/repos/charmbracelet/bubbletea/releases",
+     "title": "name", "link": "html_url", "id": "id"},
+    {"name": "auth", "type": "file", "path": "/var/log/auth.log", "interval": "1m"},
+    {"name": "disk", "type": "command", "command": "df -h | awk '$5+0 > 90'", "interval": "30m"}
+  ],
+  "rules": [
+    {"name": "familiar", "keywords": ["claude", "agent"], "sources": ["hn"]},
+    {"name": "release", "regex": "v\\d+\\.\\d+\\.\\d+", "sources": ["releases"]},
+    {"name": "intrusion", "keywords": ["Failed password"], "sources": ["auth"]},
+    {"name": "disk full", "regex": ".", "sources": ["disk"]}
+  ]
+}
+`
+
+// WriteExample writes the example config to path unless it exists.
+func WriteExample(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(exampleConfig); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,265 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// Inbox is the TUI over the alert store. It reloads periodically so a
+// running daemon's new alerts appear without restarting.
+type Inbox struct {
+	store    *Store
+	alerts   []Alert // Newest first
+	cursor   int
+	offset   int
+	open     bool // Detail view for the alert under the cursor
+	err      error
+	width    int
+	height   int
+	ready    bool
+	theme    theme.Theme
+	renderer *lipgloss.Renderer
+}
+
+type reloadMsg struct{}
+
+func reloadTick() tea.Cmd {
+	return tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
+		return reloadMsg{}
+	})
+}
+
+func NewInbox(r *lipgloss.Renderer, store *Store, th theme.Theme) Inbox {
+	m := Inbox{store: store, theme: th, renderer: r}
+	m.reload()
+	return m
+}
+
+func (m *Inbox) reload() {
+	st, err := m.store.Load()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.alerts = make([]Alert, 0, len(st.Alerts))
+	for i := len(st.Alerts) - 1; i >= 0; i-- {
+		m.alerts = append(m.alerts, st.Alerts[i])
+	}
+	if m.cursor >= len(m.alerts) {
+		m.cursor = len(m.alerts) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// mutate applies fn to the stored alert matching a, then reloads.
+func (m *Inbox) mutate(a Alert, fn func(st *State, i int)) {
+	m.err = m.store.Update(func(st *State) {
+		for i := range st.Alerts {
+			if st.Alerts[i].At.Equal(a.At) && st.Alerts[i].Item.Key() == a.Item.Key() {
+				fn(st, i)
+				return
+			}
+		}
+	})
+	m.reload()
+}
+
+func (m Inbox) Init() tea.Cmd {
+	return reloadTick()
+}
+
+func (m Inbox) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ready = true
+
+	case reloadMsg:
+		m.reload()
+		return m, reloadTick()
+
+	case tea.KeyMsg:
+		if m.open {
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m, tea.Quit
+			case "esc", "enter", "backspace":
+				m.open = false
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.alerts)-1 {
+				m.cursor++
+			}
+		case "enter", " ":
+			if m.cursor < len(m.alerts) {
+				m.open = true
+				m.mutate(m.alerts[m.cursor], func(st *State, i int) {
+					st.Alerts[i].Read = true
+				})
+			}
+		case "u":
+			if m.cursor < len(m.alerts) {
+				m.mutate(m.alerts[m.cursor], func(st *State, i int) {
+					st.Alerts[i].Read = !st.Alerts[i].Read
+				})
+			}
+		case "d":
+			if m.cursor < len(m.alerts) {
+				m.mutate(m.alerts[m.cursor], func(st *State, i int) {
+					st.Alerts = append(st.Alerts[:i], st.Alerts[i+1:]...)
+				})
+			}
+		case "r":
+			m.err = m.store.Update(func(st *State) {
+				for i := range st.Alerts {
+					st.Alerts[i].Read = true
+				}
+			})
+			m.reload()
+		}
+	}
+
+	h := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+h {
+		m.offset = m.cursor - h + 1
+	}
+	return m, nil
+}
+
+func (m Inbox) listHeight() int {
+	h := m.height - 5
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+func (m Inbox) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	r := m.renderer
+	if r == nil {
+		r = lipgloss.DefaultRenderer()
+	}
+	th := m.theme
+	quiet := theme.Degraded(r)
+	titleStyle := r.NewStyle().Foreground(th.Accent).Bold(true)
+	headStyle := r.NewStyle().Foreground(th.Muted).Faint(quiet)
+	unreadStyle := r.NewStyle().Foreground(th.Text).Bold(true)
+	readStyle := r.NewStyle().Foreground(th.Faded).Faint(quiet)
+	cursorStyle := r.NewStyle().Foreground(th.Title).Bold(true)
+	ruleStyle := r.NewStyle().Foreground(th.Highlight)
+
+	var b strings.Builder
+	unread := 0
+	for _, a := range m.alerts {
+		if !a.Read {
+			unread++
+		}
+	}
+	b.WriteString(titleStyle.Render("॥ MONITOR THE SITUATION ॥"))
+	b.WriteString(headStyle.Render(fmt.Sprintf("  %d alerts · %d unread", len(m.alerts), unread)))
+	b.WriteString("\n\n")
+
+	if m.err != nil {
+		b.WriteString(ruleStyle.Render("store: " + m.err.Error()))
+		b.WriteString("\n\n")
+	}
+
+	if m.open && m.cursor < len(m.alerts) {
+		a := m.alerts[m.cursor]
+		b.WriteString(ruleStyle.Render("["+a.Rule+"]") + " " + headStyle.Render(a.Item.Source+" · "+a.At.Local().Format("2006-01-02 15:04")))
+		b.WriteString("\n\n")
+		b.WriteString(unreadStyle.Render(a.Item.Title))
+		b.WriteString("\n")
+		if a.Item.Link != "" {
+			b.WriteString(cursorStyle.Render(a.Item.Link))
+			b.WriteString("\n")
+		}
+		if a.Item.Text != "" {
+			b.WriteString("\n")
+			b.WriteString(readStyle.Width(m.width).Render(excerpt(a.Item.Text, 600)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(headStyle.Render("esc back · q quit"))
+		return b.String()
+	}
+
+	if len(m.alerts) == 0 {
+		b.WriteString(readStyle.Render("Nothing to report. The situation is calm."))
+		b.WriteString("\n")
+	}
+
+	h := m.listHeight()
+	for i := m.offset; i < len(m.alerts) && i < m.offset+h; i++ {
+		a := m.alerts[i]
+		mark := " "
+		style := readStyle
+		if !a.Read {
+			mark = "●"
+			style = unreadStyle
+		}
+		row := fmt.Sprintf("%s %s  %-12s %s", mark, a.At.Local().Format("01-02 15:04"), "["+a.Rule+"]", a.Item.Title)
+		if w := m.width - 2; w > 1 && len([]rune(row)) > w {
+			row = string([]rune(row)[:w-1]) + "…"
+		}
+		if i == m.cursor {
+			b.WriteString(cursorStyle.Render("► " + row))
+		} else {
+			b.WriteString(style.Render("  " + row))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(headStyle.Render("enter open · u toggle read · r all read · d delete · q quit"))
+	return b.String()
+}
+
+// excerpt flattens whitespace and strips tags from feed HTML.
+func excerpt(s string, max int) string {
+	var b strings.Builder
+	inTag := false
+	for _, r := range s {
+		switch {
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+			b.WriteRune(' ')
+		case !inTag:
+			b.WriteRune(r)
+		}
+	}
+	out := strings.Join(strings.Fields(b.String()), " ")
+	if len([]rune(out)) > max {
+		out = string([]rune(out)[:max-1]) + "…"
+	}
+	return out
+}
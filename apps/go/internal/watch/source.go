@@ -0,0 +1,280 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Item is one entry pulled from a source.
+type Item struct {
+	Source string `json:"source"`
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Link   string `json:"link,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+// Key identifies an item across polls for deduplication.
+func (it Item) Key() string {
+	return it.Source + "\x00" + it.ID
+}
+
+// maxBody caps how much of a response or file is read per poll.
+const maxBody = 8 << 20
+
+// Fetcher pulls items from sources. Client is swappable so sources can
+// be pointed at a local stand-in server.
+type Fetcher struct {
+	Client *http.Client
+}
+
+// Fetch returns the current items of a source.
+func (f Fetcher) Fetch(ctx context.Context, s Source) ([]Item, error) {
+	switch s.Kind {
+	case KindFeed:
+		body, err := f.get(ctx, s.URL)
+		if err != nil {
+			return nil, err
+		}
+		return parseFeed(s.Name, body)
+	case KindJSON:
+		body, err := f.get(ctx, s.URL)
+		if err != nil {
+			return nil, err
+		}
+		return parseJSON(s, body)
+	case KindFile:
+		file, err := os.Open(s.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		body, err := readTail(file, maxBody)
+		if err != nil {
+			return nil, err
+		}
+		return lineItems(s.Name, body), nil
+	case KindCommand:
+		out, err := exec.CommandContext(ctx, "sh", "-c", s.Command).Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		return lineItems(s.Name, out), nil
+	}
+	return nil, fmt.Errorf("unknown source type %q", s.Kind)
+}
+
+func (f Fetcher) get(ctx context.Context, url string) ([]byte, error) {
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cybertantra-watch")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBody))
+}
+
+// readTail reads the last n bytes of a file, where a log gets its new
+// lines. When that starts mid-file, the cut first line is dropped.
+func readTail(file *os.File, n int64) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	off := max(0, info.Size()-n)
+	body, err := io.ReadAll(io.NewSectionReader(file, off, n))
+	if err != nil {
+		return nil, err
+	}
+	if off > 0 {
+		if i := bytes.IndexByte(body, '\n'); i >= 0 {
+			body = body[i+1:]
+		} else {
+			body = nil
+		}
+	}
+	return body, nil
+}
+
+// feedDoc decodes both RSS 2.0 and Atom; only one half is populated.
+type feedDoc struct {
+	Items []struct {
+		Title       string `xml:"title"`
+		Link        string `xml:"link"`
+		GUID        string `xml:"guid"`
+		Description string `xml:"description"`
+	} `xml:"channel>item"`
+	Entries []struct {
+		Title   string `xml:"title"`
+		ID      string `xml:"id"`
+		Summary string `xml:"summary"`
+		Content string `xml:"content"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+func parseFeed(source string, body []byte) ([]Item, error) {
+	var doc feedDoc
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	var items []Item
+	for _, it := range doc.Items {
+		id := it.GUID
+		if id == "" {
+			id = it.Link
+		}
+		if id == "" {
+			id = textID(it.Title, it.Description)
+		}
+		items = append(items, Item{Source: source, ID: id, Title: strings.TrimSpace(it.Title), Link: it.Link, Text: it.Description})
+	}
+	for _, e := range doc.Entries {
+		link := ""
+		for _, l := range e.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		text := e.Summary
+		if text == "" {
+			text = e.Content
+		}
+		id := e.ID
+		if id == "" {
+			id = link
+		}
+		if id == "" {
+			id = textID(e.Title, text)
+		}
+		items = append(items, Item{Source: source, ID: id, Title: strings.TrimSpace(e.Title), Link: link, Text: text})
+	}
+	return items, nil
+}
+
+// textID identifies an item that has neither an id nor a link by its
+// title and text, kept apart so "ab"+"c" and "a"+"bc" differ.
+func textID(title, text string) string {
+	return hash(title + "\x00" + text)
+}
+
+// parseJSON walks s.Items to an array and reads each element's fields.
+// Without an items path the document itself must be the array.
+func parseJSON(s Source, body []byte) ([]Item, error) {
+	// Numbers stay as written, so a large numeric id isn't rounded
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name, err)
+	}
+	arr, ok := lookup(doc, s.Items).([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: %q is not an array", s.Name, s.Items)
+	}
+
+	titleKey, linkKey, idKey := s.Title, s.Link, s.ID
+	if titleKey == "" {
+		titleKey = "title"
+	}
+	if linkKey == "" {
+		linkKey = "url"
+	}
+
+	var items []Item
+	for _, el := range arr {
+		it := Item{
+			Source: s.Name,
+			Title:  stringify(lookup(el, titleKey)),
+			Link:   stringify(lookup(el, linkKey)),
+		}
+		if idKey != "" {
+			it.ID = stringify(lookup(el, idKey))
+		}
+		raw, _ := json.Marshal(el)
+		it.Text = string(raw)
+		if it.ID == "" {
+			it.ID = hash(it.Text)
+		}
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// lookup follows a dotted path through decoded JSON objects.
+func lookup(v any, path string) any {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+func stringify(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// lineItems turns each non-empty line into an item keyed by its content,
+// so a line alerts once no matter how often the file is re-read.
+func lineItems(source string, body []byte) []Item {
+	var items []Item
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		items = append(items, Item{Source: source, ID: hash(line), Title: line})
+	}
+	return items
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
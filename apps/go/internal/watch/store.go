@@ -0,0 +1,155 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+)
+
+// Store limits, so a chatty source can't grow the file forever.
+const (
+	maxSeen   = 20000
+	maxAlerts = 1000
+)
+
+// Alert is a rule match waiting in the inbox.
+type Alert struct {
+	Rule string    `json:"rule"`
+	Item Item      `json:"item"`
+	At   time.Time `json:"at"`
+	Read bool      `json:"read"`
+}
+
+// State is everything the watcher persists between polls.
+type State struct {
+	Seen   map[string]time.Time `json:"seen"`   // Item keys already processed
+	Primed map[string]time.Time `json:"primed"` // Sources polled at least once
+	Alerts []Alert              `json:"alerts"` // Newest last
+}
+
+// Store is the on-disk state file. The daemon and the inbox share it,
+// so every change goes through Update under a lock file.
+type Store struct {
+	path string
+}
+
+// DefaultStorePath is the state file inside the data dir.
+func DefaultStorePath() string {
+	return datadir.Path("watch", "state.json")
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Load reads the current state; a missing file is an empty state.
+func (s *Store) Load() (State, error) {
+	st := State{Seen: make(map[string]time.Time), Primed: make(map[string]time.Time)}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, err
+	}
+	if st.Seen == nil {
+		st.Seen = make(map[string]time.Time)
+	}
+	if st.Primed == nil {
+		st.Primed = make(map[string]time.Time)
+	}
+	return st, nil
+}
+
+// Update applies fn to a freshly loaded state and writes it back.
+func (s *Store) Update(fn func(*State)) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st, err := s.Load()
+	if err != nil {
+		return err
+	}
+	fn(&st)
+	st.prune()
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// lock takes an exclusive lock file, breaking it when stale.
+func (s *Store) lock() (func(), error) {
+	path := s.path + ".lock"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > 30*time.Second {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("watch store is locked: " + path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// prune drops the oldest seen keys and alerts beyond the caps.
+func (st *State) prune() {
+	if over := len(st.Alerts) - maxAlerts; over > 0 {
+		st.Alerts = append([]Alert(nil), st.Alerts[over:]...)
+	}
+	over := len(st.Seen) - maxSeen
+	if over <= 0 {
+		return
+	}
+	keys := make([]string, 0, len(st.Seen))
+	for k := range st.Seen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return st.Seen[keys[i]].Before(st.Seen[keys[j]])
+	})
+	for _, k := range keys[:over] {
+		delete(st.Seen, k)
+	}
+}
+
+// Unread counts alerts not yet opened.
+func (st State) Unread() int {
+	n := 0
+	for _, a := range st.Alerts {
+		if !a.Read {
+			n++
+		}
+	}
+	return n
+}
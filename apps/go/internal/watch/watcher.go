@@ -0,0 +1,158 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+// Watcher polls sources on their intervals, matches rules and records
+// new alerts in the store.
+type Watcher struct {
+	Config  Config
+	Store   *Store
+	Fetcher Fetcher
+	Notify  io.Writer // Terminal for OSC notifications; nil disables them
+	Log     *log.Logger
+	Now     func() time.Time
+
+	next map[string]time.Time
+}
+
+func New(cfg Config, store *Store) *Watcher {
+	return &Watcher{
+		Config: cfg,
+		Store:  store,
+		Log:    log.New(io.Discard, "", 0),
+		Now:    time.Now,
+		next:   make(map[string]time.Time),
+	}
+}
+
+// Run polls until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		w.pollDue(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) pollDue(ctx context.Context) {
+	now := w.Now()
+	for _, src := range w.Config.Sources {
+		if now.Before(w.next[src.Name]) {
+			continue
+		}
+		w.next[src.Name] = now.Add(time.Duration(src.Interval))
+		if _, err := w.Poll(ctx, src); err != nil {
+			w.Log.Printf("poll %s: %v", src.Name, err)
+		}
+	}
+}
+
+// PollAll polls every source once and returns the new alerts.
+func (w *Watcher) PollAll(ctx context.Context) ([]Alert, error) {
+	var all []Alert
+	var errs []string
+	for _, src := range w.Config.Sources {
+		alerts, err := w.Poll(ctx, src)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", src.Name, err))
+			continue
+		}
+		all = append(all, alerts...)
+	}
+	if len(errs) > 0 {
+		return all, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return all, nil
+}
+
+// Poll fetches one source, deduplicates against the store and records
+// alerts for unseen items that match a rule. The first poll of a source
+// only primes the store, so existing backlog doesn't flood the inbox.
+func (w *Watcher) Poll(ctx context.Context, src Source) ([]Alert, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	items, err := w.Fetcher.Fetch(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	now := w.Now()
+	var alerts []Alert
+	err = w.Store.Update(func(st *State) {
+		_, primed := st.Primed[src.Name]
+		st.Primed[src.Name] = now
+		for _, it := range items {
+			key := it.Key()
+			if _, seen := st.Seen[key]; seen {
+				continue
+			}
+			st.Seen[key] = now
+			if !primed {
+				continue
+			}
+			for _, r := range w.Config.Rules {
+				if r.Match(it) {
+					a := Alert{Rule: r.Name, Item: it, At: now}
+					st.Alerts = append(st.Alerts, a)
+					alerts = append(alerts, a)
+					break
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range alerts {
+		w.Log.Printf("alert [%s] %s: %s", a.Rule, a.Item.Source, a.Item.Title)
+		w.notify(a)
+	}
+	return alerts, nil
+}
+
+// notify emits a desktop notification through the terminal:
+// OSC 9 (iTerm2, Windows Terminal, WezTerm) and/or OSC 777 (urxvt, foot, Ghostty).
+func (w *Watcher) notify(a Alert) {
+	if w.Notify == nil {
+		return
+	}
+	title := sanitize("cybertantra · " + a.Rule)
+	body := sanitize(a.Item.Title)
+	switch w.Config.Notify {
+	case "osc9":
+		fmt.Fprintf(w.Notify, "\x1b]9;%s: %s\x07", title, body)
+	case "osc777":
+		fmt.Fprintf(w.Notify, "\x1b]777;notify;%s;%s\x07", title, body)
+	case "both":
+		fmt.Fprintf(w.Notify, "\x1b]9;%s: %s\x07", title, body)
+		fmt.Fprintf(w.Notify, "\x1b]777;notify;%s;%s\x07", title, body)
+	}
+}
+
+// sanitize strips control characters and the OSC 777 field separator.
+func sanitize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == ';' {
+			return ' '
+		}
+		return r
+	}, s)
+	if len([]rune(s)) > 200 {
+		s = string([]rune(s)[:199]) + "…"
+	}
+	return s
+}
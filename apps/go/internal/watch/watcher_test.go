@@ -0,0 +1,189 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// feedServer stands in for a site, serving an Atom feed whose entries
+// carry neither an id nor a link, so only their text tells them apart.
+type feedServer struct {
+	mu      sync.Mutex
+	entries []string // Entry titles, newest last
+}
+
+func (f *feedServer) add(title string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, title)
+}
+
+func (f *feedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?><feed xmlns="http://www.w3.org/2005/Atom"><title>stand-in</title>`)
+	for _, title := range f.entries {
+		fmt.Fprintf(&b, `<entry><title>%s</title><summary>about %s</summary></entry>`, title, title)
+	}
+	b.WriteString(`</feed>`)
+	w.Header().Set("Content-Type", "application/atom+xml")
+	fmt.Fprint(w, b.String())
+}
+
+func TestPollFeed(t *testing.T) {
+	site := &feedServer{}
+	site.add("karma and old news")
+	srv := httptest.NewServer(site)
+	defer srv.Close()
+
+	cfg := Config{
+		Sources: []Source{{Name: "site", Kind: KindFeed, URL: srv.URL}},
+		Rules:   []Rule{{Name: "karma", Keywords: []string{"karma"}}},
+		Notify:  "off",
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	w := New(cfg, NewStore(filepath.Join(t.TempDir(), "state.json")))
+	w.Fetcher = Fetcher{Client: srv.Client()}
+	ctx := context.Background()
+
+	// The first poll primes the store: the backlog raises nothing
+	alerts, err := w.PollAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("priming poll raised %d alerts, want 0", len(alerts))
+	}
+
+	site.add("fresh karma")
+	site.add("more karma")
+	site.add("weather")
+	alerts, err = w.PollAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, a := range alerts {
+		titles = append(titles, a.Item.Title)
+	}
+	if got, want := strings.Join(titles, ", "), "fresh karma, more karma"; got != want {
+		t.Fatalf("alerts = %q, want %q", got, want)
+	}
+
+	// Items already seen are not raised again
+	alerts, err = w.PollAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("repeat poll raised %d alerts, want 0", len(alerts))
+	}
+
+	st, err := w.Store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Seen) != 4 || len(st.Alerts) != 2 {
+		t.Fatalf("store has %d seen and %d alerts, want 4 and 2", len(st.Seen), len(st.Alerts))
+	}
+}
+
+func TestParseFeedIDs(t *testing.T) {
+	body := []byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+<entry><title>a</title><id>urn:a</id></entry>
+<entry><title>b</title><link href="https://example.com/b"/></entry>
+<entry><title>c</title><summary>one</summary></entry>
+<entry><title>c</title><summary>two</summary></entry>
+</feed>`)
+	items, err := parseFeed("site", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("parsed %d items, want 4", len(items))
+	}
+	if items[0].ID != "urn:a" || items[1].ID != "https://example.com/b" {
+		t.Errorf("ids = %q, %q; want the id, then the link", items[0].ID, items[1].ID)
+	}
+	if items[2].ID == "" || items[2].ID == items[3].ID {
+		t.Errorf("entries without an id or link got ids %q and %q, want distinct ones", items[2].ID, items[3].ID)
+	}
+
+	// RSS items fall back the same way, so two with one title differ
+	body = []byte(`<rss><channel>
+<item><title>c</title><description>one</description></item>
+<item><title>c</title><description>two</description></item>
+</channel></rss>`)
+	items, err = parseFeed("site", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID == items[1].ID {
+		t.Errorf("rss items without a guid or link got ids %+v, want two distinct ones", items)
+	}
+}
+
+func TestParseJSONNumbers(t *testing.T) {
+	src := Source{Name: "api", Items: "data", ID: "id"}
+	items, err := parseJSON(src, []byte(`{"data":[{"id":9007199254740993,"title":"big"},{"id":1.5,"title":"half"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].ID != "9007199254740993" || items[1].ID != "1.5" {
+		t.Errorf("ids = %+v, want 9007199254740993 and 1.5 as written", items)
+	}
+}
+
+func TestFetchFileTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	var b strings.Builder
+	for b.Len() <= maxBody {
+		b.WriteString("old line padding the log out past the read limit\n")
+	}
+	b.WriteString("newest\n")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := Source{Name: "log", Kind: KindFile, Path: path}
+	items, err := Fetcher{}.Fetch(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 || items[len(items)-1].Title != "newest" {
+		t.Fatalf("last of %d items is not the newest line", len(items))
+	}
+	if items[0].Title != items[1].Title {
+		t.Errorf("first item %q is a cut line", items[0].Title)
+	}
+
+	// A truncated file is read from its start again
+	if err := os.WriteFile(path, []byte("fresh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	items, err = Fetcher{}.Fetch(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Title != "fresh" {
+		t.Errorf("items after truncation = %+v, want just fresh", items)
+	}
+}
+
+func TestValidateNotify(t *testing.T) {
+	for notify, ok := range map[string]bool{"osc9": true, "osc777": true, "both": true, "off": true, "": false, "OSC9": false, "bell": false} {
+		cfg := Config{Notify: notify}
+		if err := cfg.validate(); (err == nil) != ok {
+			t.Errorf("notify %q: err = %v, want ok %v", notify, err, ok)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/gorkolas/cybertantra/internal/datadir"
+	"github.com/gorkolas/cybertantra/internal/journal"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// runJournal works with the practice journal outside the TUI.
+func runJournal(args []string) error {
+	usage := func() {
+		fmt.Println("Usage: cybertantra journal COMMAND [flags]")
+		fmt.Println("\n  export [-o FILE] [QUERY...]        write entries as markdown (QUERY: words and #tags)")
+		fmt.Println("  add [-sealed] [-mood N] [TEXT...]  append an entry (TEXT or stdin; mood 1-5)")
+		fmt.Println("  moods                              average mood per palette, from the palette log")
+		fmt.Println("  encrypt                            protect the journal with a passphrase")
+		fmt.Println("  seal-init [-identity-out FILE]     create the familiar's sealed journal")
+		fmt.Println("  read -sealed -identity FILE        read the sealed journal as its key-holder")
+		fmt.Println("\nThe passphrase is read from CYBERTANTRA_JOURNAL_PASSPHRASE or prompted for.")
+	}
+	if len(args) == 0 {
+		usage()
+		return usagef("missing journal subcommand")
+	}
+	if isHelp(args[0]) {
+		usage()
+		return flag.ErrHelp
+	}
+
+	fs := flag.NewFlagSet("journal "+args[0], flag.ContinueOnError)
+	dir := fs.String("dir", datadir.Path("journal"), "journal `directory`")
+	out := fs.String("o", "", "write to `file` instead of stdout")
+	sealed := fs.Bool("sealed", false, "use the familiar's sealed journal")
+	identity := fs.String("identity", "", "age identity `file` of the sealed journal's key-holder (- for stdin)")
+	identityOut := fs.String("identity-out", "", "write the new identity to `file` instead of stdout")
+	mood := fs.Int("mood", 0, "mood from 1 (heavy) to 5 (light)")
+	paletteLog := fs.String("palette-log", datadir.Path("palette.jsonl"), "palette log `file` to correlate moods with")
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+	path := *dir
+	if *sealed {
+		path = filepath.Join(*dir, journal.SealedDir)
+	}
+
+	switch args[0] {
+	case "seal-init":
+		id, err := journal.InitSealed(filepath.Join(*dir, journal.SealedDir))
+		if err != nil {
+			return err
+		}
+		if *identityOut != "" {
+			if err := os.WriteFile(*identityOut, []byte(id+"\n"), 0600); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Identity written to %s. Give it to your familiar, then delete your copy.\n", *identityOut)
+			return nil
+		}
+		fmt.Fprintln(os.Stderr, "This identity is the only key to the sealed journal. Give it to your familiar; do not keep it.")
+		fmt.Println(id)
+		return nil
+	}
+
+	j, err := journal.Open(path)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "encrypt":
+		if j.Sealed() || j.Encrypted() {
+			return errors.New("journal is already encrypted")
+		}
+		pass, err := readPassphrase("New passphrase: ")
+		if err != nil {
+			return err
+		}
+		again, err := readPassphrase("Again: ")
+		if err != nil {
+			return err
+		}
+		if pass != again {
+			return errors.New("passphrases differ")
+		}
+		if err := j.Encrypt(pass); err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stderr, "Journal encrypted. There is no recovery without the passphrase.")
+		return nil
+
+	case "add":
+		text := strings.Join(fs.Args(), " ")
+		if text == "" {
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			text = string(data)
+		}
+		if err := unlock(j); err != nil {
+			return err
+		}
+		_, err := j.Add(journal.Entry{Text: text, Mood: *mood})
+		return err
+
+	case "moods":
+		if err := unlock(j); err != nil {
+			return err
+		}
+		entries, err := j.Entries()
+		if err != nil {
+			return err
+		}
+		palettes, err := theme.OpenLog(*paletteLog).Entries()
+		if err != nil {
+			return err
+		}
+		return writeMoods(os.Stdout, entries, palettes)
+
+	case "export", "read":
+		if j.Sealed() {
+			if *identity == "" {
+				return journal.ErrSealed
+			}
+			var data []byte
+			if *identity == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(*identity)
+			}
+			if err != nil {
+				return err
+			}
+			id, err := journal.ParseIdentity(string(data))
+			if err != nil {
+				return err
+			}
+			if err := j.UnlockSealed(id); err != nil {
+				return err
+			}
+		} else if err := unlock(j); err != nil {
+			return err
+		}
+
+		entries, err := j.Entries()
+		if err != nil {
+			return err
+		}
+		entries = journal.Search(entries, strings.Join(fs.Args(), " "))
+
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return journal.ExportMarkdown(w, entries)
+
+	default:
+		usage()
+		return usagef("unknown journal subcommand %q", args[0])
+	}
+}
+
+// writeMoods reports the average mood of entries written under each
+// palette, so the effect of lighting on the psyche can be tracked.
+func writeMoods(w io.Writer, entries []journal.Entry, palettes []theme.LogEntry) error {
+	type tally struct {
+		n, sum int
+	}
+	byPalette := make(map[string]*tally)
+	var names []string
+	for _, e := range entries {
+		if e.Mood == 0 {
+			continue
+		}
+		name, ok := theme.ActiveAt(palettes, e.Time)
+		if !ok || name == "" {
+			name = "(outside a session)"
+		}
+		t := byPalette[name]
+		if t == nil {
+			t = &tally{}
+			byPalette[name] = t
+			names = append(names, name)
+		}
+		t.n++
+		t.sum += e.Mood
+	}
+	if len(names) == 0 {
+		_, err := fmt.Fprintln(w, "No entries with a mood yet. Add a \"mood: 1-5\" line to an entry, or use journal add -mood N.")
+		return err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := byPalette[name]
+		if _, err := fmt.Fprintf(w, "%-22s %4.1f  (%d entries)\n", name, float64(t.sum)/float64(t.n), t.n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unlock opens an encrypted journal; plain and sealed journals pass through.
+func unlock(j *journal.Journal) error {
+	if !j.Locked() {
+		return nil
+	}
+	pass, err := readPassphrase("Journal passphrase: ")
+	if err != nil {
+		return err
+	}
+	return j.Unlock(pass)
+}
+
+func readPassphrase(prompt string) (string, error) {
+	if pass := os.Getenv("CYBERTANTRA_JOURNAL_PASSPHRASE"); pass != "" {
+		return pass, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New("no terminal to ask for the passphrase; set CYBERTANTRA_JOURNAL_PASSPHRASE")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	pass, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	return string(pass), err
+}
@@ -1,22 +1,154 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+)
 
-	tea "github.com/charmbracelet/bubbletea"
+// version is stamped at build time:
+//
+//	go build -ldflags "-X main.version=v0.3.0"
+var version = "dev"
 
-	"github.com/gorkolas/cybertantra/internal/app"
+// Exit codes, for scripts.
+const (
+	exitOK    = 0
+	exitError = 1 // The command ran and failed
+	exitUsage = 2 // Bad command line
 )
 
+// command is one cybertantra subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands in help order. Without a command, cybertantra reads.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"read", "[-section N] [-plain] [-accessible]", "open the reader, or print it when piped (the default)", runRead},
+		{"serve", "ssh|web [-addr ADDR]", "serve the reader over SSH or in the browser", runServe},
+		{"search", "[-limit N] QUERY...", "find where a word or phrase appears in the manifesto", runSearch},
+		{"highlights", "[-format md|json] [-o FILE]", "write out the lines you highlighted while reading", runHighlights},
+		{"export", "[-o FILE] [-format md|txt|html|epub]", "write the invocation out for e-readers and browsers", runExport},
+		{"mantra", "[-random] [-format NAME] [-fortune]", "print the day's mantra for prompts, MOTDs and fortune", runMantra},
+		{"journal", "COMMAND", "export, add to, encrypt or seal the practice journal", runJournal},
+		{"declutter", "MAILBOX...", "triage senders and plan unsubscribes", runDeclutter},
+		{"watch", "init|run|once|inbox", "monitor the situation: poll sources against rules", runWatch},
+		{"altar", "build|serve [SRC]", "build or preview a static personal site", runAltar},
+		{"version", "", "print the version", runVersion},
+		{"help", "[COMMAND]", "show help for cybertantra or a command", runHelp},
+	}
+}
+
 func main() {
-	p := tea.NewProgram(
-		app.New(nil),
-		tea.WithAltScreen(),
-	)
+	os.Exit(run(os.Args[1:]))
+}
 
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v", err)
-		os.Exit(1)
+func run(args []string) int {
+	name := "read"
+	if len(args) > 0 {
+		switch args[0] {
+		case "-h", "-help", "--help":
+			name, args = "help", nil
+		case "-version", "--version":
+			name, args = "version", nil
+		default:
+			if !strings.HasPrefix(args[0], "-") {
+				name, args = args[0], args[1:]
+			}
+		}
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
+	return exitCode(cmd.run(args))
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+// usageError is a bad command line rather than a failed run.
+type usageError struct {
+	err      error
+	reported bool // The flag package already printed it
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// usagef reports a bad command line.
+func usagef(format string, a ...any) error {
+	return usageError{err: fmt.Errorf(format, a...)}
+}
+
+// parseFlags parses args into fs, marking bad flags as usage errors.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return usageError{err: err, reported: true}
+}
+
+// isHelp reports whether a subcommand slot asks for help instead.
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func exitCode(err error) int {
+	var usage usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		if !usage.reported {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: cybertantra [COMMAND] [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %-36s %s\n", c.name, c.args, c.summary)
+	}
+	fmt.Fprintln(w, "\nRun \"cybertantra help COMMAND\" for a command's flags.")
+	fmt.Fprintln(w, "Exit status is 0 on success, 1 when a command fails and 2 for a bad command line.")
+}
+
+// runHelp prints the command list, or a command's own help.
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return nil
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok || cmd.name == "help" {
+		return usagef("unknown command %q", args[0])
 	}
+	return cmd.run([]string{"-h"})
 }
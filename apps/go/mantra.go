@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/mantra"
+)
+
+// mantraFormats are the named -format templates. Those without a
+// trailing newline sit inside something else: a prompt, a status bar.
+var mantraFormats = map[string]string{
+	"line":    "{{.Text}}\n",
+	"prompt":  "॥ {{.Text}} ॥ ",
+	"tmux":    "॥ {{.Text}} ॥",
+	"motd":    "\n  ॥ {{.Text}} ॥\n\n      — {{.Section}}\n\n",
+	"fortune": "{{.Text}}\n\t\t-- Cybertantra, {{.Section}}\n",
+}
+
+// mantraData is what -format templates see.
+type mantraData struct {
+	mantra.Mantra
+	Date string
+}
+
+// runMantra prints the day's mantra, or writes them all as a fortune
+// database.
+func runMantra(args []string) error {
+	fs := flag.NewFlagSet("mantra", flag.ContinueOnError)
+	random := fs.Bool("random", false, "pick at random instead of by date")
+	date := fs.String("date", "", "pick for `YYYY-MM-DD` instead of today")
+	format := fs.String("format", "line", "`TEMPLATE`: line, prompt, tmux, motd, fortune, or a Go template over .Text, .Section and .Date")
+	fortune := fs.Bool("fortune", false, "write every mantra as a fortune file instead; with -o, its .dat index too")
+	out := fs.String("o", "", "write to `FILE` instead of stdout")
+	noNewline := fs.Bool("n", false, "don't end a custom -format with a newline")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cybertantra mantra [flags]")
+		fmt.Fprintln(fs.Output(), "\nOne line a day from the invocation's key and bold lines.")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected argument %q", fs.Arg(0))
+	}
+
+	list := mantra.All(invocation.Sections())
+	if *fortune {
+		return writeFortune(list, *out)
+	}
+
+	day := time.Now()
+	if *date != "" {
+		t, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+		if err != nil {
+			return usagef("-date wants YYYY-MM-DD, not %q", *date)
+		}
+		day = t
+	}
+	m := mantra.ForDay(list, day)
+	if *random {
+		m = mantra.Random(list)
+	}
+
+	text, ok := mantraFormats[*format]
+	if !ok {
+		text = *format
+		if !*noNewline && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+	}
+	tmpl, err := template.New("mantra").Parse(text)
+	if err != nil {
+		return usagef("-format: %v", err)
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := tmpl.Execute(w, mantraData{Mantra: m, Date: day.Format("2006-01-02")}); err != nil {
+		return err
+	}
+	if *out != "" {
+		return w.Close()
+	}
+	return nil
+}
+
+// writeFortune writes the fortune file, and its strfile index beside it
+// when it goes to a file: fortune reads PATH together with PATH.dat.
+func writeFortune(list []mantra.Mantra, path string) error {
+	if path == "" {
+		return mantra.WriteFortune(os.Stdout, list)
+	}
+	for _, f := range []struct {
+		path  string
+		write func(*os.File) error
+	}{
+		{path, func(f *os.File) error { return mantra.WriteFortune(f, list) }},
+		{path + ".dat", func(f *os.File) error { return mantra.WriteStrfile(f, list) }},
+	} {
+		file, err := os.Create(f.path)
+		if err != nil {
+			return err
+		}
+		if err := f.write(file); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
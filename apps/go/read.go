@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+
+	"github.com/gorkolas/cybertantra/internal/app"
+	"github.com/gorkolas/cybertantra/internal/datadir"
+	"github.com/gorkolas/cybertantra/internal/highlight"
+	"github.com/gorkolas/cybertantra/internal/invocation"
+	"github.com/gorkolas/cybertantra/internal/journal"
+	"github.com/gorkolas/cybertantra/internal/keys"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// runRead opens the reader in the terminal, at the menu or straight
+// into a section of the invocation. When stdout is not a terminal, or
+// with -plain, the invocation is printed as text instead.
+func runRead(args []string) error {
+	fs := flag.NewFlagSet("read", flag.ContinueOnError)
+	section := fs.Int("section", 0, fmt.Sprintf("open the invocation at section `N` (1-%d)", len(invocation.Sections())))
+	themeName := fs.String("theme", os.Getenv(theme.EnvVar), "start with the theme called `NAME` ("+theme.EnvVar+")")
+	plain := fs.Bool("plain", false, "print the text instead of opening the reader (the default when stdout is not a terminal)")
+	width := fs.Int("width", 0, "wrap printed text to `N` columns (default: the terminal, $COLUMNS or 72)")
+	color := fs.String("color", "auto", "colour printed text: `WHEN` is auto, always or never")
+	accessible := fs.Bool("accessible", invocation.Accessible(os.Getenv(invocation.AccessibleEnvVar)), "read line by line for screen readers, without animation ("+invocation.AccessibleEnvVar+")")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cybertantra read [flags]")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("unexpected argument %q", fs.Arg(0))
+	}
+	if *section < 0 || *section > len(invocation.Sections()) {
+		return usagef("section must be between 1 and %d", len(invocation.Sections()))
+	}
+	if *color != "auto" && *color != "always" && *color != "never" {
+		return usagef("unknown -color %q (want auto, always or never)", *color)
+	}
+	tty := term.IsTerminal(int(os.Stdout.Fd()))
+	if *plain || (!tty && !*accessible) {
+		return printPlain(*section, *width, *color, *themeName, tty)
+	}
+
+	j, err := journal.Open(datadir.Path("journal"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Journal unavailable: %v\n", err)
+	}
+	hs, err := highlight.Open(datadir.Path("highlights.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Highlights unavailable: %v\n", err)
+	}
+	if *accessible {
+		return invocation.ReadLinear(os.Stdin, os.Stdout, *section, invocation.Options{
+			Journal:    j,
+			Progress:   datadir.Path("invocation.json"),
+			Highlights: hs,
+		})
+	}
+
+	th, themes := sessionTheme(*themeName)
+	circadian, err := theme.LoadCircadian(theme.CircadianPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Circadian: %v\n", err)
+	}
+	km, err := keys.Load(keys.Path())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Keys: %v\n", err)
+	}
+	palettes := theme.OpenLog(datadir.Path("palette.jsonl"))
+	p := tea.NewProgram(
+		app.New(nil, app.Options{
+			Journal:    j,
+			Theme:      th,
+			Themes:     themes,
+			Keys:       km,
+			Circadian:  circadian.Override(os.Getenv(theme.CircadianEnvVar)),
+			PaletteLog: palettes,
+			Section:    *section,
+			Progress:   datadir.Path("invocation.json"),
+			Pace:       datadir.Path("pace.json"),
+			Highlights: hs,
+			Links:      theme.Links(os.Getenv),
+		}),
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+
+	_, err = p.Run()
+	palettes.Record("", theme.SourceEnd)
+	return err
+}
+
+// printPlain writes the invocation, or one section of it, to stdout
+// as typeset text. Colour follows the terminal under auto, so a pipe
+// gets none; always colours at CYBERTANTRA_COLOR's depth, or truecolor.
+func printPlain(section, width int, color, themeName string, tty bool) error {
+	sections := invocation.Sections()
+	if section > 0 {
+		sections = sections[section-1 : section]
+	}
+	if width <= 0 {
+		width = plainWidth(tty)
+	}
+
+	r := lipgloss.NewRenderer(os.Stdout)
+	switch color {
+	case "never":
+		r.SetColorProfile(termenv.Ascii)
+	case "always":
+		p, ok := theme.ParseProfile(os.Getenv(theme.ColorEnvVar))
+		if !ok || p == termenv.Ascii {
+			p = termenv.TrueColor
+		}
+		r.SetColorProfile(p)
+	default:
+		if !tty {
+			r.SetColorProfile(termenv.Ascii)
+		} else if p, ok := theme.ParseProfile(os.Getenv(theme.ColorEnvVar)); ok {
+			r.SetColorProfile(p)
+		}
+	}
+
+	themes, err := theme.All(theme.Dir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Themes: %v\n", err)
+	}
+	th, err := theme.Resolve(themes, themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Themes: %v\n", err)
+	}
+	return invocation.WriteText(os.Stdout, sections, invocation.TextOptions{Width: width, Renderer: r, Theme: th})
+}
+
+// plainWidth is the terminal's width when there is one, then $COLUMNS,
+// then a comfortable reading measure.
+func plainWidth(tty bool) int {
+	if tty {
+		if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+			return w
+		}
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 72
+}
+
+// sessionTheme loads the user's themes and picks the named one,
+// settling the terminal's colour profile on the way. A broken theme
+// file is reported but never stops the program.
+func sessionTheme(name string) (theme.Theme, []theme.Theme) {
+	themes, err := theme.All(theme.Dir())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Themes: %v\n", err)
+	}
+	if theme.Prepare(lipgloss.DefaultRenderer(), os.Getenv(theme.ColorEnvVar)) {
+		themes = theme.PlainAll(themes)
+	}
+	th, err := theme.Resolve(themes, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Themes: %v\n", err)
+	}
+	return th, themes
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
+
+	"github.com/gorkolas/cybertantra/internal/search"
+	"github.com/gorkolas/cybertantra/internal/theme"
+)
+
+// runSearch prints where a word or phrase appears in the manifesto,
+// best first, with the matches picked out on a terminal.
+func runSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "print at most `N` results; 0 prints all")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cybertantra search [flags] QUERY...")
+		fmt.Fprintln(fs.Output(), "\nSearches the invocation's titles, key lines, text, reflections and notes,")
+		fmt.Fprintln(fs.Output(), "then the headings and lines of the parts after it, rituals included. The")
+		fmt.Fprintln(fs.Output(), "last word also matches the start of longer words. Open an invocation")
+		fmt.Fprintln(fs.Output(), "result with cybertantra read -section N; the rest are in the Manifesto")
+		fmt.Fprintln(fs.Output(), "page of the menu.")
+		fmt.Fprintln(fs.Output(), "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(query) == "" {
+		return usagef("search needs a query")
+	}
+
+	results := search.Default().Search(query)
+	if len(results) == 0 {
+		return fmt.Errorf("nothing matches %q", query)
+	}
+	if *limit > 0 && len(results) > *limit {
+		results = results[:*limit]
+	}
+
+	// Without a terminal the styles fall away on their own
+	tty := term.IsTerminal(int(os.Stdout.Fd()))
+	r := lipgloss.NewRenderer(os.Stdout)
+	if p, ok := theme.ParseProfile(os.Getenv(theme.ColorEnvVar)); ok && tty {
+		r.SetColorProfile(p)
+	}
+	th := theme.Default()
+	placeStyle := r.NewStyle().Foreground(th.Muted)
+	hitStyle := r.NewStyle().Foreground(th.Highlight).Bold(true)
+
+	width := plainWidth(tty) - 2
+	for _, res := range results {
+		text, matches := res.Snippet(width)
+		fmt.Println(placeStyle.Render(res.Place()))
+		fmt.Println("  " + search.Mark(text, matches, func(piece string, hit bool) string {
+			if hit {
+				return hitStyle.Render(piece)
+			}
+			return piece
+		}))
+	}
+	return nil
+}
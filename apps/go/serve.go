@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gorkolas/cybertantra/internal/server"
+)
+
+// runServe serves the reader to others: over SSH, or in the browser
+// through a terminal running this binary's own reader.
+func runServe(args []string) error {
+	usage := func() {
+		fmt.Println("Usage: cybertantra serve <target> [flags]")
+		fmt.Println("\nTargets:")
+		fmt.Println("  ssh   [-addr 0.0.0.0:2222] [-host-key PATH]   serve the reader over SSH")
+		fmt.Println("  web   [-addr :8080]                           serve the reader in the browser")
+	}
+	if len(args) == 0 {
+		usage()
+		return usagef("serve needs a target")
+	}
+	if isHelp(args[0]) {
+		usage()
+		return flag.ErrHelp
+	}
+
+	switch args[0] {
+	case "ssh":
+		fs := flag.NewFlagSet("serve ssh", flag.ContinueOnError)
+		addr := fs.String("addr", "0.0.0.0:2222", "listen on `ADDR`")
+		hostKey := fs.String("host-key", ".ssh/id_ed25519", "host key `PATH`, generated when missing")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		return server.ServeSSH(server.SSHConfig{Addr: *addr, HostKey: *hostKey})
+	case "web":
+		fs := flag.NewFlagSet("serve web", flag.ContinueOnError)
+		addr := fs.String("addr", ":8080", "listen on `ADDR`")
+		if err := parseFlags(fs, args[1:]); err != nil {
+			return err
+		}
+		self, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("finding the reader to serve: %w", err)
+		}
+		return server.ServeWeb(server.WebConfig{Addr: *addr, Command: []string{self, "read"}})
+	default:
+		usage()
+		return usagef("unknown serve target %q", args[0])
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/gorkolas/cybertantra/internal/theme"
+	"github.com/gorkolas/cybertantra/internal/watch"
+)
+
+// runWatch drives the "monitor the situation" watcher: a polling daemon,
+// a one-shot poll, and the alert inbox.
+func runWatch(args []string) error {
+	usage := func() {
+		fmt.Println("Usage: cybertantra watch init|run|once|inbox [-config FILE] [-store FILE]")
+		fmt.Println("\n  init   write an example config")
+		fmt.Println("  run    poll sources on their intervals until interrupted")
+		fmt.Println("  once   poll every source once and print new alerts")
+		fmt.Println("  inbox  browse alerts")
+	}
+	if len(args) == 0 {
+		usage()
+		return usagef("missing watch subcommand")
+	}
+	if isHelp(args[0]) {
+		usage()
+		return flag.ErrHelp
+	}
+
+	fs := flag.NewFlagSet("watch "+args[0], flag.ContinueOnError)
+	configPath := fs.String("config", watch.ConfigPath(), "rules `file`")
+	storePath := fs.String("store", watch.DefaultStorePath(), "alert store `file`")
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+	store := watch.NewStore(*storePath)
+
+	switch args[0] {
+	case "init":
+		if err := os.MkdirAll(filepath.Dir(*configPath), 0700); err != nil {
+			return err
+		}
+		if err := watch.WriteExample(*configPath); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s — edit the sources and rules, then run: cybertantra watch run\n", *configPath)
+		return nil
+
+	case "inbox":
+		th, _ := sessionTheme(os.Getenv(theme.EnvVar))
+		p := tea.NewProgram(watch.NewInbox(nil, store, th), tea.WithAltScreen())
+		_, err := p.Run()
+		return err
+
+	case "run", "once":
+		cfg, err := watch.LoadConfig(*configPath)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no config at %s (create one with: cybertantra watch init)", *configPath)
+		}
+		if err != nil {
+			return err
+		}
+		w := watch.New(cfg, store)
+		w.Log = log.New(os.Stderr, "watch: ", log.LstdFlags)
+
+		if args[0] == "once" {
+			alerts, err := w.PollAll(context.Background())
+			for _, a := range alerts {
+				fmt.Printf("[%s] %s: %s %s\n", a.Rule, a.Item.Source, a.Item.Title, a.Item.Link)
+			}
+			return err
+		}
+
+		w.Notify = os.Stdout
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		w.Log.Printf("watching %d sources with %d rules", len(cfg.Sources), len(cfg.Rules))
+		return w.Run(ctx)
+
+	default:
+		usage()
+		return usagef("unknown watch subcommand %q", args[0])
+	}
+}